@@ -200,7 +200,10 @@ func main() {
 
 // registerAdapters registers all transcription and diarization adapters with config-based paths
 func registerAdapters(cfg *config.Config) {
-	logger.Info("Registering adapters with environment path", "whisperx_env", cfg.WhisperXEnv)
+	logger.Info("Registering adapters with environment path", "whisperx_env", cfg.WhisperXEnv, "adapter_env_root", cfg.AdapterEnvRoot)
+
+	// Move auto-registered adapters (MLX) under the configured root
+	adapters.SetEnvRoot(cfg.AdapterEnvRoot)
 
 	// Shared environment path for NVIDIA models (NeMo-based)
 	nvidiaEnvPath := filepath.Join(cfg.WhisperXEnv, "parakeet")
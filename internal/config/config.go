@@ -33,6 +33,8 @@ type Config struct {
 	// Python/WhisperX configuration
 	UVPath      string
 	WhisperXEnv string
+	// Root for auto-registered adapter environments (e.g. <root>/mlx-env)
+	AdapterEnvRoot string
 
 	// OpenAI configuration
 	OpenAIAPIKey string
@@ -54,6 +56,7 @@ func Load() *Config {
 		TranscriptsDir: getEnv("TRANSCRIPTS_DIR", "data/transcripts"),
 		UVPath:         findUVPath(),
 		WhisperXEnv:    getEnv("WHISPERX_ENV", "data/whisperx-env"),
+		AdapterEnvRoot: getEnv("ADAPTER_ENV_ROOT", "data"),
 		OpenAIAPIKey:   getEnv("OPENAI_API_KEY", ""),
 	}
 }
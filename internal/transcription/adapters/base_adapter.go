@@ -1,7 +1,9 @@
 package adapters
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"io"
@@ -29,6 +31,18 @@ var (
 
 // CheckEnvironmentReady checks if a UV environment is ready with caching and singleflight
 func CheckEnvironmentReady(envPath, importStatement string) bool {
+	return CheckEnvironmentReadyContext(context.Background(), envPath, importStatement)
+}
+
+// CheckEnvironmentReadyContext is CheckEnvironmentReady with a context that
+// stops the check. A check stopped by ctx reports false and is not cached.
+func CheckEnvironmentReadyContext(ctx context.Context, envPath, importStatement string) bool {
+	return checkEnvironmentReady(ctx, execRunner{}, envPath, importStatement)
+}
+
+// checkEnvironmentReady is CheckEnvironmentReadyContext running the check
+// through r
+func checkEnvironmentReady(ctx context.Context, r CommandRunner, envPath, importStatement string) bool {
 	cacheKey := fmt.Sprintf("%s:%s", envPath, importStatement)
 
 	// Check cache first
@@ -50,8 +64,11 @@ func CheckEnvironmentReady(envPath, importStatement string) bool {
 		envCacheMutex.RUnlock()
 
 		// Run the actual check
-		testCmd := exec.Command("uv", "run", "--native-tls", "--project", envPath, "python", "-c", importStatement)
-		ready := testCmd.Run() == nil
+		testCmd := exec.CommandContext(ctx, "uv", "run", "--native-tls", "--project", envPath, "python", "-c", importStatement)
+		ready := r.Run(ctx, testCmd) == nil
+		if ctx.Err() != nil {
+			return false, nil
+		}
 
 		// Cache the result
 		envCacheMutex.Lock()
@@ -64,6 +81,47 @@ func CheckEnvironmentReady(envPath, importStatement string) bool {
 	return result.(bool)
 }
 
+// Logger receives structured adapter log events. level is one of "debug",
+// "info", "warn" or "error".
+type Logger interface {
+	Log(level, msg string, fields map[string]any)
+}
+
+// defaultLogger forwards events to the application logger
+type defaultLogger struct{}
+
+func (defaultLogger) Log(level, msg string, fields map[string]any) {
+	args := make([]any, 0, 2*len(fields))
+	for key, value := range fields {
+		args = append(args, key, value)
+	}
+	switch level {
+	case "debug":
+		logger.Debug(msg, args...)
+	case "warn":
+		logger.Warn(msg, args...)
+	case "error":
+		logger.Error(msg, args...)
+	default:
+		logger.Info(msg, args...)
+	}
+}
+
+// CommandRunner runs the external commands an adapter builds. The command
+// is fully configured (Args, Env, Stdout, Stderr, ...) but not started, so a
+// fake runner can inspect it and write canned output instead of running it.
+// Implementations must be safe for concurrent use.
+type CommandRunner interface {
+	Run(ctx context.Context, cmd *exec.Cmd) error
+}
+
+// execRunner runs commands on the host
+type execRunner struct{}
+
+func (execRunner) Run(_ context.Context, cmd *exec.Cmd) error {
+	return cmd.Run()
+}
+
 // BaseAdapter provides common functionality for all model adapters
 type BaseAdapter struct {
 	modelID      string
@@ -71,6 +129,8 @@ type BaseAdapter struct {
 	capabilities interfaces.ModelCapabilities
 	schema       []interfaces.ParameterSchema
 	initialized  bool
+	logger       Logger
+	runner       CommandRunner
 }
 
 // NewBaseAdapter creates a new base adapter
@@ -84,6 +144,58 @@ func NewBaseAdapter(modelID, modelPath string, capabilities interfaces.ModelCapa
 	}
 }
 
+// SetLogger sends the adapter's structured log events to l. A nil l restores
+// the application logger.
+func (b *BaseAdapter) SetLogger(l Logger) {
+	b.logger = l
+}
+
+// SetCommandRunner makes the adapter run its subprocesses through r, e.g. a
+// fake in tests. A nil r restores running them on the host.
+func (b *BaseAdapter) SetCommandRunner(r CommandRunner) {
+	b.runner = r
+}
+
+// commandRunner returns the configured CommandRunner
+func (b *BaseAdapter) commandRunner() CommandRunner {
+	if b.runner == nil {
+		return execRunner{}
+	}
+	return b.runner
+}
+
+// runCommand runs cmd through the configured CommandRunner
+func (b *BaseAdapter) runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	return b.commandRunner().Run(ctx, cmd)
+}
+
+// commandOutput is like cmd.Output but goes through runCommand
+func (b *BaseAdapter) commandOutput(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := b.runCommand(ctx, cmd)
+	return stdout.Bytes(), err
+}
+
+// commandCombinedOutput is like cmd.CombinedOutput but goes through
+// runCommand
+func (b *BaseAdapter) commandCombinedOutput(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := b.runCommand(ctx, cmd)
+	return output.Bytes(), err
+}
+
+// log sends one structured event to the configured Logger
+func (b *BaseAdapter) log(level, msg string, fields map[string]any) {
+	l := b.logger
+	if l == nil {
+		l = defaultLogger{}
+	}
+	l.Log(level, msg, fields)
+}
+
 // GetCapabilities returns the model capabilities
 func (b *BaseAdapter) GetCapabilities() interfaces.ModelCapabilities {
 	return b.capabilities
@@ -94,6 +206,33 @@ func (b *BaseAdapter) GetParameterSchema() []interfaces.ParameterSchema {
 	return b.schema
 }
 
+// ParameterSchemaJSON serializes the parameter schema for building forms:
+//
+//	{"model_id": "...", "groups": ["basic", ...], "parameters": [...]}
+//
+// Parameters keep their schema order and groups are listed in the order they
+// first appear, so the output is stable across calls.
+func (b *BaseAdapter) ParameterSchemaJSON() ([]byte, error) {
+	var groups []string
+	seen := make(map[string]bool)
+	for _, p := range b.schema {
+		if !seen[p.Group] {
+			seen[p.Group] = true
+			groups = append(groups, p.Group)
+		}
+	}
+
+	data, err := json.Marshal(struct {
+		ModelID    string                       `json:"model_id"`
+		Groups     []string                     `json:"groups"`
+		Parameters []interfaces.ParameterSchema `json:"parameters"`
+	}{b.modelID, groups, b.schema})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode parameter schema: %w", err)
+	}
+	return data, nil
+}
+
 // GetModelPath returns the model file path
 func (b *BaseAdapter) GetModelPath() string {
 	return b.modelPath
@@ -137,6 +276,47 @@ func (b *BaseAdapter) ValidateParameters(params map[string]interface{}) error {
 	return nil
 }
 
+// ValidateAndApplyDefaults is a strict form of ValidateParameters for use at
+// the start of a transcription: unknown parameters are rejected rather than
+// logged, and the returned copy of params has the schema default filled in
+// for every optional parameter that was not provided. Nil values count as
+// not provided.
+func (b *BaseAdapter) ValidateAndApplyDefaults(params map[string]interface{}) (map[string]interface{}, error) {
+	known := make(map[string]bool, len(b.schema))
+	for _, paramSchema := range b.schema {
+		known[paramSchema.Name] = true
+	}
+	for paramName := range params {
+		if !known[paramName] {
+			names := make([]string, 0, len(b.schema))
+			for _, paramSchema := range b.schema {
+				names = append(names, paramSchema.Name)
+			}
+			return nil, fmt.Errorf("unknown parameter %q for model %s: allowed parameters are %s", paramName, b.modelID, strings.Join(names, ", "))
+		}
+	}
+
+	resolved := make(map[string]interface{}, len(b.schema))
+	for _, paramSchema := range b.schema {
+		value := params[paramSchema.Name]
+		if value == nil {
+			if paramSchema.Required {
+				return nil, fmt.Errorf("required parameter missing: %s", paramSchema.Name)
+			}
+			if paramSchema.Default != nil {
+				resolved[paramSchema.Name] = paramSchema.Default
+			}
+			continue
+		}
+
+		if err := b.validateParameterValue(paramSchema, value); err != nil {
+			return nil, fmt.Errorf("invalid value for parameter %s: %w", paramSchema.Name, err)
+		}
+		resolved[paramSchema.Name] = value
+	}
+	return resolved, nil
+}
+
 // validateParameterValue validates a single parameter value against its schema
 func (b *BaseAdapter) validateParameterValue(schema interfaces.ParameterSchema, value interface{}) error {
 	// Type validation
@@ -186,6 +366,30 @@ func (b *BaseAdapter) validateParameterValue(schema interfaces.ParameterSchema,
 		}
 		// Additional slice validation could be added here
 
+	case "object":
+		if _, err := b.convertToMap(value); err != nil {
+			return err
+		}
+
+	case "[][2]float64":
+		if _, err := b.convertToRanges(value); err != nil {
+			return err
+		}
+
+	case "[]float64":
+		values, err := b.convertToFloatList(value)
+		if err != nil {
+			return err
+		}
+		for _, v := range values {
+			if schema.Min != nil && v < *schema.Min {
+				return fmt.Errorf("value %g is below minimum %g", v, *schema.Min)
+			}
+			if schema.Max != nil && v > *schema.Max {
+				return fmt.Errorf("value %g is above maximum %g", v, *schema.Max)
+			}
+		}
+
 	default:
 		return fmt.Errorf("unsupported parameter type: %s", schema.Type)
 	}
@@ -233,6 +437,77 @@ func (b *BaseAdapter) convertToFloat(value interface{}) (float64, error) {
 	}
 }
 
+// convertToFloatList accepts a single number, a comma-separated string such as
+// "0.0,0.2,0.4" or a list of numbers
+func (b *BaseAdapter) convertToFloatList(value interface{}) ([]float64, error) {
+	switch v := value.(type) {
+	case []float64:
+		return v, nil
+	case []interface{}:
+		values := make([]float64, len(v))
+		for i, item := range v {
+			f, err := b.convertToFloat(item)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			values[i] = f
+		}
+		return values, nil
+	case string:
+		parts := strings.Split(v, ",")
+		values := make([]float64, 0, len(parts))
+		for _, part := range parts {
+			f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return nil, fmt.Errorf("expected comma-separated numbers, got %q", v)
+			}
+			values = append(values, f)
+		}
+		return values, nil
+	default:
+		f, err := b.convertToFloat(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected number or list of numbers, got %T", value)
+		}
+		return []float64{f}, nil
+	}
+}
+
+// convertToMap accepts a JSON object either as a decoded map or as a JSON string
+func (b *BaseAdapter) convertToMap(value interface{}) (map[string]interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case string:
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(v), &obj); err != nil {
+			return nil, fmt.Errorf("expected JSON object: %w", err)
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("expected object, got %T", value)
+	}
+}
+
+// convertToRanges converts a list of [start, end] pairs, given as a Go value
+// or a JSON string, to [][2]float64
+func (b *BaseAdapter) convertToRanges(value interface{}) ([][2]float64, error) {
+	data, ok := value.(string)
+	if !ok {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected list of [start, end] pairs, got %T", value)
+		}
+		data = string(encoded)
+	}
+
+	var ranges [][2]float64
+	if err := json.Unmarshal([]byte(data), &ranges); err != nil {
+		return nil, fmt.Errorf("expected list of [start, end] pairs: %w", err)
+	}
+	return ranges, nil
+}
+
 // stringInSlice checks if a string is in a slice of strings
 func (b *BaseAdapter) stringInSlice(str string, slice []string) bool {
 	for _, s := range slice {
@@ -357,6 +632,42 @@ func (b *BaseAdapter) GetBoolParameter(params map[string]interface{}, paramName
 	return false
 }
 
+// GetRangesParameter safely gets a list of [start, end] pairs
+func (b *BaseAdapter) GetRangesParameter(params map[string]interface{}, paramName string) [][2]float64 {
+	value := b.GetParameterWithDefault(params, paramName)
+	if value == nil {
+		return nil
+	}
+	if ranges, err := b.convertToRanges(value); err == nil {
+		return ranges
+	}
+	return nil
+}
+
+// GetFloatListParameter safely gets a list of floats
+func (b *BaseAdapter) GetFloatListParameter(params map[string]interface{}, paramName string) []float64 {
+	value := b.GetParameterWithDefault(params, paramName)
+	if value == nil {
+		return nil
+	}
+	if values, err := b.convertToFloatList(value); err == nil {
+		return values
+	}
+	return nil
+}
+
+// GetMapParameter safely gets an object parameter
+func (b *BaseAdapter) GetMapParameter(params map[string]interface{}, paramName string) map[string]interface{} {
+	value := b.GetParameterWithDefault(params, paramName)
+	if value == nil {
+		return nil
+	}
+	if obj, err := b.convertToMap(value); err == nil {
+		return obj
+	}
+	return nil
+}
+
 // GetStringSliceParameter safely gets a []string parameter
 func (b *BaseAdapter) GetStringSliceParameter(params map[string]interface{}, paramName string) []string {
 	value := b.GetParameterWithDefault(params, paramName)
@@ -384,6 +695,32 @@ func (b *BaseAdapter) CreateTempDirectory(procCtx interfaces.ProcessingContext)
 	return tempDir, nil
 }
 
+// SpoolAudioInput writes a Reader-backed input to a file in dir and returns an
+// input pointing at it. Inputs that already have a FilePath are returned as is.
+func (b *BaseAdapter) SpoolAudioInput(input interfaces.AudioInput, dir string) (interfaces.AudioInput, error) {
+	if input.FilePath != "" || input.Reader == nil {
+		return input, nil
+	}
+
+	path := filepath.Join(dir, "input."+strings.ToLower(input.Format))
+	f, err := os.Create(path)
+	if err != nil {
+		return input, fmt.Errorf("failed to create spooled audio file: %w", err)
+	}
+	size, err := io.Copy(f, input.Reader)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return input, fmt.Errorf("failed to spool audio input: %w", err)
+	}
+
+	input.FilePath = path
+	input.Size = size
+	input.Reader = nil
+	return input, nil
+}
+
 // CleanupTempDirectory removes temporary files
 func (b *BaseAdapter) CleanupTempDirectory(tempDir string) {
 	if tempDir != "" {
@@ -452,9 +789,16 @@ func (b *BaseAdapter) ReadLogTail(logPath string, maxBytes int64) (string, error
 
 // ValidateAudioInput checks if the audio input meets model requirements
 func (b *BaseAdapter) ValidateAudioInput(input interfaces.AudioInput) error {
+	streamed := input.FilePath == "" && input.Reader != nil
+	if input.FilePath == "" && input.Reader == nil {
+		return fmt.Errorf("audio input has neither a file path nor a reader")
+	}
+
 	// Check if file exists
-	if _, err := os.Stat(input.FilePath); os.IsNotExist(err) {
-		return fmt.Errorf("audio file not found: %s", input.FilePath)
+	if !streamed {
+		if _, err := os.Stat(input.FilePath); os.IsNotExist(err) {
+			return fmt.Errorf("audio file not found: %s", input.FilePath)
+		}
 	}
 
 	// Check supported formats
@@ -472,8 +816,8 @@ func (b *BaseAdapter) ValidateAudioInput(input interfaces.AudioInput) error {
 		}
 	}
 
-	// Check file size (basic sanity check)
-	if input.Size == 0 {
+	// Check file size (basic sanity check); a stream's size is not known
+	if !streamed && input.Size == 0 {
 		return fmt.Errorf("audio file appears to be empty")
 	}
 
@@ -502,27 +846,27 @@ func (b *BaseAdapter) CreateDefaultMetadata(params map[string]interface{}) map[s
 
 // LogProcessingStart logs the start of processing
 func (b *BaseAdapter) LogProcessingStart(input interfaces.AudioInput, procCtx interfaces.ProcessingContext) {
-	logger.Info("Starting model processing",
-		"model_id", b.modelID,
-		"job_id", procCtx.JobID,
-		"audio_file", input.FilePath,
-		"audio_format", input.Format,
-		"audio_duration", input.Duration,
-		"audio_size", input.Size)
+	b.log("info", "Starting model processing", map[string]any{
+		"model_id":       b.modelID,
+		"job_id":         procCtx.JobID,
+		"audio_file":     input.FilePath,
+		"audio_format":   input.Format,
+		"audio_duration": input.Duration,
+		"audio_size":     input.Size,
+	})
 }
 
 // LogProcessingEnd logs the end of processing
 func (b *BaseAdapter) LogProcessingEnd(procCtx interfaces.ProcessingContext, processingTime time.Duration, err error) {
+	fields := map[string]any{
+		"model_id":        b.modelID,
+		"job_id":          procCtx.JobID,
+		"processing_time": processingTime,
+	}
 	if err != nil {
-		logger.Error("Model processing failed",
-			"model_id", b.modelID,
-			"job_id", procCtx.JobID,
-			"processing_time", processingTime,
-			"error", err)
+		fields["error"] = err
+		b.log("error", "Model processing failed", fields)
 	} else {
-		logger.Info("Model processing completed",
-			"model_id", b.modelID,
-			"job_id", procCtx.JobID,
-			"processing_time", processingTime)
+		b.log("info", "Model processing completed", fields)
 	}
 }
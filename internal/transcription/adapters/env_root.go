@@ -0,0 +1,129 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/internal/transcription/registry"
+)
+
+// DefaultEnvRoot is the directory auto-registered adapters keep their
+// environments under until SetEnvRoot is called
+const DefaultEnvRoot = "./data"
+
+// autoAdapter is an adapter that registers itself at package init
+type autoAdapter struct {
+	id     string
+	subdir string
+	create func(envPath string) interfaces.TranscriptionAdapter
+}
+
+var (
+	envRootMu    sync.Mutex
+	envRoot      = DefaultEnvRoot
+	autoAdapters []autoAdapter
+)
+
+// registerAutoAdapter registers an adapter under <env root>/<subdir> and
+// remembers how to build it so SetEnvRoot can move it later. It panics if id
+// is already taken, since two adapters would silently shadow each other.
+func registerAutoAdapter(id, subdir string, create func(envPath string) interfaces.TranscriptionAdapter) {
+	envRootMu.Lock()
+	defer envRootMu.Unlock()
+
+	if err := registry.RegisterTranscriptionAdapterE(id, create(filepath.Join(envRoot, subdir))); err != nil {
+		panic(fmt.Sprintf("cannot auto-register adapter %q: %v", id, err))
+	}
+	autoAdapters = append(autoAdapters, autoAdapter{id: id, subdir: subdir, create: create})
+}
+
+// SetEnvRoot sets the root directory for all auto-registered adapter
+// environments and re-registers them under it, each in its own subdirectory
+// (e.g. <path>/mlx-env). Call it during startup, before any jobs run.
+func SetEnvRoot(path string) {
+	envRootMu.Lock()
+	defer envRootMu.Unlock()
+
+	envRoot = path
+	for _, a := range autoAdapters {
+		registry.Unregister(a.id)
+		if err := registry.RegisterTranscriptionAdapterE(a.id, a.create(filepath.Join(path, a.subdir))); err != nil {
+			panic(fmt.Sprintf("cannot re-register adapter %q: %v", a.id, err))
+		}
+	}
+}
+
+// EnvRoot returns the current root directory for adapter environments
+func EnvRoot() string {
+	envRootMu.Lock()
+	defer envRootMu.Unlock()
+	return envRoot
+}
+
+// ErrUnsafeEnvPath is returned by ValidateEnvPath for paths that are empty or
+// climb out of their base with ".."
+var ErrUnsafeEnvPath = errors.New("unsafe environment path")
+
+// ValidateEnvPath checks that path can hold an adapter environment and
+// returns it as an absolute path, so that later changes of the working
+// directory do not move it. Paths containing ".." are rejected, as the path
+// may come from configuration or user input, and so is the filesystem root.
+// It does not touch the filesystem; checkEnvPathWritable does.
+func ValidateEnvPath(path string) (string, error) {
+	if strings.TrimSpace(path) == "" {
+		return "", fmt.Errorf("%w: path is empty", ErrUnsafeEnvPath)
+	}
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == filepath.Separator }) {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %s contains \"..\"", ErrUnsafeEnvPath, path)
+		}
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve environment path %s: %w", path, err)
+	}
+	if filepath.Dir(abs) == abs {
+		return "", fmt.Errorf("%w: %s is the filesystem root", ErrUnsafeEnvPath, abs)
+	}
+	return abs, nil
+}
+
+// checkEnvPathWritable checks that the environment directory abs can be
+// created and written to. The directory need not exist yet, but its nearest
+// existing ancestor must be a writable directory.
+func checkEnvPathWritable(abs string) error {
+	dir := abs
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("environment path %s: %s is not a directory", abs, dir)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("environment path %s: %w", abs, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fmt.Errorf("environment path %s has no existing parent", abs)
+		}
+		dir = parent
+	}
+
+	// Permission bits do not tell the whole story (ACLs, read-only mounts),
+	// so try to create a file
+	probe, err := os.CreateTemp(dir, ".scriberr-write-test-*")
+	if err != nil {
+		return fmt.Errorf("environment path %s: %s is not writable: %w", abs, dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
@@ -1,34 +1,138 @@
 package adapters
 
 import (
+	"bytes"
 	"context"
+	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"scriberr/internal/transcription/interfaces"
-	"scriberr/internal/transcription/registry"
+	"scriberr/pkg/logger"
+
+	"github.com/google/uuid"
 )
 
+// PinnedMLXWhisperVersion is the mlx-whisper release installed into new
+// environments. Unpinned installs built at different times can disagree on
+// the output format.
+const PinnedMLXWhisperVersion = "0.4.2"
+
 // MLXAdapter implements the TranscriptionAdapter interface for Apple MLX
 type MLXAdapter struct {
 	*BaseAdapter
 	envPath string
+
+	// envPathErr is why envPath is unusable, if it is; PrepareEnvironment
+	// returns it before doing any work
+	envPathErr error
+
+	// mlxWhisperPin is the mlx-whisper version to install; empty installs the
+	// latest release
+	mlxWhisperPin string
+
+	// readOnlyCacheDir is a pre-populated Hugging Face hub cache that must not
+	// be written to (e.g. a shared read-only mount)
+	readOnlyCacheDir string
+
+	// offlineCacheDir is HF_HOME for air-gapped use; the network is never
+	// touched while it is set
+	offlineCacheDir string
+
+	// allowedModels restricts the model parameter when non-empty;
+	// modelSchemaDefaults keeps the original model schema to restore
+	allowedModels       []string
+	modelSchemaDefaults *interfaces.ParameterSchema
+
+	// tempRoot is the ProcessingContext.TempDirectory jobs use, for
+	// CleanupStaleTempDirs; staleTempAge > 0 runs it from PrepareEnvironment
+	tempRoot     string
+	staleTempAge time.Duration
+
+	// maxDuration bounds each Transcribe call; zero means no limit
+	maxDuration time.Duration
+
+	// resultCacheDir holds results keyed by audio content and parameters;
+	// empty disables the cache
+	resultCacheDir string
+
+	// downloadSem bounds concurrent model downloads independently of inference;
+	// downloadMu also guards approver
+	downloadMu  sync.Mutex
+	downloadSem chan struct{}
+	approver    DownloadApprover
+
+	// retryPolicy governs retries of transient subprocess failures
+	retryPolicy RetryPolicy
+
+	// warmupModels are downloaded by PrepareEnvironment
+	warmupModels []string
+
+	// transcribeSem bounds concurrent model subprocesses so parallel jobs do
+	// not exhaust unified memory; transcribeMu guards it
+	transcribeMu  sync.Mutex
+	transcribeSem chan struct{}
+
+	// stateMu guards the initialized flag for cheap concurrent Ready() probes
+	// and the cached mlx-whisper version
+	stateMu           sync.RWMutex
+	mlxWhisperVersion string
+
+	// healthMu guards the cached HealthCheck result
+	healthMu        sync.Mutex
+	healthErr       error
+	healthCheckedAt time.Time
+
+	// catalogMu guards the model list cached for GetSupportedModels
+	catalogMu         sync.Mutex
+	catalog           []ModelInfo
+	catalogExpires    time.Time
+	catalogRefreshing bool
+
+	// probes caches ffprobe results by file path, size and modification time
+	probeMu sync.Mutex
+	probes  map[string]audioProbe
+
+	// translator handles target languages other than English
+	translator interfaces.Translator
+
+	// analyzer optionally labels segments after transcription
+	analyzer interfaces.SegmentAnalyzer
+
+	// activeJobs maps running job IDs to their control directories
+	jobsMu     sync.Mutex
+	activeJobs map[string]string
 }
 
 // NewMLXAdapter creates a new MLX adapter
 func NewMLXAdapter(envPath string) *MLXAdapter {
+	return NewMLXAdapterWithVersion(envPath, PinnedMLXWhisperVersion)
+}
+
+// NewMLXAdapterWithVersion creates an MLX adapter that installs the given
+// mlx-whisper version (e.g. "0.4.2") instead of the default pin. An empty
+// version installs the latest release. envPath is resolved to an absolute
+// path; if ValidateEnvPath rejects it, PrepareEnvironment fails with that
+// error. Whether it is writable is only checked by PrepareEnvironment, as
+// adapters are built at init.
+func NewMLXAdapterWithVersion(envPath, version string) *MLXAdapter {
 	capabilities := interfaces.ModelCapabilities{
-		ModelID:     "mlx_whisper",
-		ModelFamily: "whisper",
-		DisplayName: "Apple MLX Whisper",
-		Description: "Optimized Whisper models for Apple Silicon",
-		Version:     "1.0.0",
+		ModelID:            "mlx_whisper",
+		ModelFamily:        "whisper",
+		DisplayName:        "Apple MLX Whisper",
+		Description:        "Optimized Whisper models for Apple Silicon",
+		Version:            "1.0.0",
 		SupportedLanguages: []string{"auto", "en", "es", "fr", "de", "it", "pt", "nl", "ja", "zh", "ko"},
 		SupportedFormats:   []string{"wav", "mp3", "flac", "m4a"},
 		RequiresGPU:        false, // MLX uses Unified Memory / Neural Engine
@@ -60,7 +164,387 @@ func NewMLXAdapter(envPath string) *MLXAdapter {
 			Required:    false,
 			Default:     "4bit",
 			Options:     []string{"4bit", "8bit", "none"},
-			Description: "Model quantization level",
+			Description: "Model quantization level; 4bit and 8bit use the model's mlx-community -4bit/-8bit variant when one exists, none the full-precision weights",
+			Group:       "advanced",
+		},
+		{
+			Name:        "download_only_quantization",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Download only the weight files for the selected quantization when the model repository holds several variants",
+			Group:       "advanced",
+		},
+		{
+			Name:        "quality",
+			Type:        "string",
+			Required:    false,
+			Default:     nil,
+			Options:     []string{"draft", "balanced", "accurate"},
+			Description: "Quality preset that selects model, quantization and decoding settings; explicit parameters override it",
+			Group:       "basic",
+		},
+		{
+			Name:        "fast",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Speed-optimized settings for previews: the turbo model with greedy decoding at temperature 0, not conditioned on previous text. Overrides quality; explicit parameters override it.",
+			Group:       "basic",
+		},
+		{
+			Name:        "language",
+			Type:        "string",
+			Required:    false,
+			Default:     "auto",
+			Description: "Source language code, or auto to detect it. Forcing the language avoids misdetection on short clips",
+			Group:       "basic",
+		},
+		{
+			Name:        "task",
+			Type:        "string",
+			Required:    false,
+			Default:     "transcribe",
+			Options:     []string{"transcribe", "translate"},
+			Description: "transcribe keeps the source language; translate outputs English text",
+			Group:       "basic",
+		},
+		{
+			Name:        "word_timestamps",
+			Type:        "bool",
+			Required:    false,
+			Default:     true,
+			Description: "Compute word-level timestamps",
+			Group:       "basic",
+		},
+		{
+			Name:        "beam_size",
+			Type:        "int",
+			Required:    false,
+			Default:     nil,
+			Min:         &[]float64{1}[0],
+			Max:         &[]float64{1}[0],
+			Description: "Beam search size; mlx-whisper only implements greedy decoding, so only 1 is accepted",
+			Group:       "quality",
+		},
+		{
+			Name:        "best_of",
+			Type:        "int",
+			Required:    false,
+			Default:     nil,
+			Min:         &[]float64{1}[0],
+			Max:         &[]float64{10}[0],
+			Description: "Number of candidates to sample at non-zero temperatures, keeping the most likely (unset uses the library default)",
+			Group:       "advanced",
+		},
+		{
+			Name:        "temperature",
+			Type:        "[]float64",
+			Required:    false,
+			Default:     nil,
+			Min:         &[]float64{0.0}[0],
+			Max:         &[]float64{1.0}[0],
+			Description: "Sampling temperature, or a comma-separated fallback schedule such as 0.0,0.2,0.4 tried in turn when decoding fails (unset uses Whisper's standard schedule)",
+			Group:       "quality",
+		},
+		{
+			Name:        "seed",
+			Type:        "int",
+			Required:    false,
+			Default:     nil,
+			Min:         &[]float64{0}[0],
+			Description: "Seed the random number generators and decode at temperature 0 without fallback, for repeatable output. Results can still differ across models and mlx-whisper versions.",
+			Group:       "advanced",
+		},
+		{
+			Name:        "no_speech_threshold",
+			Type:        "float",
+			Required:    false,
+			Default:     0.6,
+			Min:         &[]float64{0.0}[0],
+			Max:         &[]float64{1.0}[0],
+			Description: "Treat a segment as silence when its no-speech probability exceeds this and decoding failed",
+			Group:       "advanced",
+		},
+		{
+			Name:        "compression_ratio_threshold",
+			Type:        "float",
+			Required:    false,
+			Default:     2.4,
+			Min:         &[]float64{1.0}[0],
+			Description: "Retry decoding at a higher temperature when the text's gzip compression ratio exceeds this (catches repetition)",
+			Group:       "advanced",
+		},
+		{
+			Name:        "initial_prompt",
+			Type:        "string",
+			Required:    false,
+			Default:     "",
+			Description: "Text that biases decoding toward its vocabulary, e.g. product names and jargon",
+			Group:       "advanced",
+		},
+		{
+			Name:        "suppress_tokens",
+			Type:        "string",
+			Required:    false,
+			Default:     nil,
+			Description: "Comma-separated token IDs the decoder may not emit; -1 stands for Whisper's default set of symbols (unset uses the library default)",
+			Group:       "advanced",
+		},
+		{
+			Name:        "suppress_blank",
+			Type:        "bool",
+			Required:    false,
+			Default:     nil,
+			Description: "Keep blank output from being sampled at the start of a segment (unset uses the library default)",
+			Group:       "advanced",
+		},
+		{
+			Name:        "condition_on_previous_text",
+			Type:        "bool",
+			Required:    false,
+			Default:     true,
+			Description: "Prompt each window with the text decoded before it, as Whisper does by default. Turning this off decodes windows independently, which is faster and keeps errors and hallucinations on noisy audio from propagating.",
+			Group:       "advanced",
+		},
+		{
+			Name:        "nan_handling",
+			Type:        "string",
+			Required:    false,
+			Default:     "omit",
+			Options:     []string{"omit", "zero"},
+			Description: "How NaN/Inf confidence values are reported: omitted (absent) or as zero",
+			Group:       "advanced",
+		},
+		{
+			Name:        "timestamp_precision",
+			Type:        "string",
+			Required:    false,
+			Default:     "full",
+			Options:     []string{"full", "ms", "cs", "ds", "s", "0", "1", "2", "3", "4", "5", "6"},
+			Description: "Rounding applied to output timestamps: a unit (ms/cs/ds/s) or a number of decimal places",
+			Group:       "advanced",
+		},
+		{
+			Name:        "repair_word_timings",
+			Type:        "bool",
+			Required:    false,
+			Default:     true,
+			Description: "Clamp word timings into their segment and keep them monotonic",
+			Group:       "advanced",
+		},
+		{
+			Name:        "malformed_word_timings",
+			Type:        "string",
+			Required:    false,
+			Default:     "clamp",
+			Options:     []string{"clamp", "drop"},
+			Description: "How repair_word_timings handles a segment whose word timings overlap, run backwards or leave the segment: clamp them, or drop the segment's word timings and keep only its own",
+			Group:       "advanced",
+		},
+		{
+			Name:        "word_confidence_threshold",
+			Type:        "float",
+			Required:    false,
+			Default:     0.0,
+			Min:         &[]float64{0}[0],
+			Max:         &[]float64{1}[0],
+			Description: "Strip trailing words with a lower probability from each segment, dropping segments with none left (0 disables; needs word timestamps)",
+			Group:       "advanced",
+		},
+		{
+			Name:        "repetition_threshold",
+			Type:        "int",
+			Required:    false,
+			Default:     0,
+			Min:         &[]float64{0}[0],
+			Description: "Collapse this many or more consecutive near-identical segments (a repetition loop) into the first one, or drop them all when every one exceeds no_speech_threshold (0 disables)",
+			Group:       "advanced",
+		},
+		{
+			Name:        "min_segment_duration",
+			Type:        "float",
+			Required:    false,
+			Default:     0.0,
+			Min:         &[]float64{0}[0],
+			Description: "Merge segments shorter than this many seconds into the following segment (0 keeps Whisper's segmentation)",
+			Group:       "advanced",
+		},
+		{
+			Name:        "merge_max_gap",
+			Type:        "float",
+			Required:    false,
+			Default:     1.0,
+			Min:         &[]float64{0}[0],
+			Description: "Never merge short segments across a silence longer than this many seconds",
+			Group:       "advanced",
+		},
+		{
+			Name:        "char_timestamps",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Add approximate character-level timings, interpolated within each word",
+			Group:       "advanced",
+		},
+		{
+			Name:        "decoding_options",
+			Type:        "object",
+			Required:    false,
+			Default:     nil,
+			Description: "JSON object of mlx_whisper DecodingOptions fields passed through to the decoder",
+			Group:       "advanced",
+		},
+		{
+			Name:        "extra_options",
+			Type:        "object",
+			Required:    false,
+			Default:     nil,
+			Description: "JSON object of keyword arguments passed as is to mlx_whisper.transcribe, for options without a parameter of their own. Other parameters win on conflicts; invalid options make the run fail.",
+			Group:       "advanced",
+		},
+		{
+			Name:        "target_language",
+			Type:        "string",
+			Required:    false,
+			Default:     nil,
+			Description: "Output language. English uses Whisper's translate task; other languages require a configured translator",
+			Group:       "basic",
+		},
+		{
+			Name:        "split_streams",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Write subprocess stdout and stderr to separate log files",
+			Group:       "advanced",
+		},
+		{
+			Name:        "skip_if_fresh",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Reuse the result saved in the output directory when it is newer than the audio and was produced with the same parameters",
+			Group:       "advanced",
+		},
+		{
+			Name:        "lightweight_turns",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Mark likely speaker changes from pitch/energy jumps between segments, without identifying speakers",
+			Group:       "advanced",
+		},
+		{
+			Name:        "text_from_segments",
+			Type:        "string",
+			Required:    false,
+			Default:     "none",
+			Options:     []string{"none", "space", "language_aware_join"},
+			Description: "Rebuild the full text from segments: none keeps the model's text, space joins with spaces, language_aware_join omits spaces for languages such as Chinese and Japanese",
+			Group:       "advanced",
+		},
+		{
+			Name:        "overlap_voting",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Transcribe twice with shifted windows and keep the more confident version around each window boundary",
+			Group:       "quality",
+		},
+		{
+			Name:        "voting_window",
+			Type:        "float",
+			Required:    false,
+			Default:     30.0,
+			Min:         &[]float64{5}[0],
+			Max:         &[]float64{600}[0],
+			Description: "Window length in seconds for overlap_voting",
+			Group:       "quality",
+		},
+		{
+			Name:        "voting_overlap",
+			Type:        "float",
+			Required:    false,
+			Default:     15.0,
+			Min:         &[]float64{1}[0],
+			Max:         &[]float64{599}[0],
+			Description: "Shift in seconds of the second pass's windows; must be less than voting_window",
+			Group:       "quality",
+		},
+		{
+			Name:        "start_from",
+			Type:        "float",
+			Required:    false,
+			Default:     0.0,
+			Min:         &[]float64{0}[0],
+			Description: "Start transcribing at this offset in seconds; timestamps stay relative to the start of the file",
+			Group:       "advanced",
+		},
+		{
+			Name:        "time_offset",
+			Type:        "float",
+			Required:    false,
+			Default:     0.0,
+			Min:         &[]float64{0}[0],
+			Description: "Seconds added to every timestamp, e.g. where a clip starts in the recording it was cut from. Only the result is shifted; the audio is transcribed as is.",
+			Group:       "advanced",
+		},
+		{
+			Name:        "ranges",
+			Type:        "[][2]float64",
+			Required:    false,
+			Default:     nil,
+			Description: "Transcribe only these [start, end] time ranges in seconds; segments keep original timestamps and record their range index",
+			Group:       "advanced",
+		},
+		{
+			Name:        "chunk_length",
+			Type:        "float",
+			Required:    false,
+			Default:     0.0,
+			Min:         &[]float64{0}[0],
+			Description: "Transcribe long audio in overlapping chunks of this many seconds and merge them (0 disables)",
+			Group:       "advanced",
+		},
+		{
+			Name:        "transcode",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Convert input formats MLX does not support (e.g. ogg, opus, webm) to 16 kHz mono WAV with ffmpeg first",
+			Group:       "advanced",
+		},
+		{
+			Name:        "recording_start_time",
+			Type:        "string",
+			Required:    false,
+			Default:     nil,
+			Description: "Wall-clock time the recording started (RFC3339); adds absolute start and end times to each segment",
+			Group:       "advanced",
+		},
+		{
+			Name:        "oom_fallback",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "If the model runs out of memory, retry with the next smaller model and record the downgrade in the result metadata",
+			Group:       "advanced",
+		},
+		{
+			Name:        "debug_dump",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Write debug.json with the resolved parameters, command line and environment to the output directory",
+			Group:       "advanced",
+		},
+		{
+			Name:        "normalize_for_training",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Add normalized text (lowercase, no punctuation, standardized spelling) using Whisper's WER normalizer",
 			Group:       "advanced",
 		},
 	}
@@ -68,29 +552,194 @@ func NewMLXAdapter(envPath string) *MLXAdapter {
 	// Adjust base path as needed
 	baseAdapter := NewBaseAdapter("mlx_whisper", filepath.Join(envPath, "MLX"), capabilities, schema)
 
+	resolved, envPathErr := ValidateEnvPath(envPath)
+	if envPathErr != nil {
+		resolved = envPath
+	}
+
 	return &MLXAdapter{
-		BaseAdapter: baseAdapter,
-		envPath:     envPath,
+		BaseAdapter:   baseAdapter,
+		envPath:       resolved,
+		envPathErr:    envPathErr,
+		mlxWhisperPin: version,
+		downloadSem:   make(chan struct{}, defaultMLXDownloadConcurrency),
+		transcribeSem: make(chan struct{}, defaultMLXConcurrency(capabilities.MemoryRequirement)),
+		retryPolicy:   defaultMLXRetryPolicy,
+		activeJobs:    make(map[string]string),
+	}
+}
+
+// ResolvedEnvPath returns the absolute environment directory the adapter uses
+func (m *MLXAdapter) ResolvedEnvPath() string {
+	return m.envPath
+}
+
+// SetReadOnlyModelCache points the adapter at a pre-populated, read-only
+// Hugging Face hub cache. Model weights are resolved from the cache without any
+// network access or lock-file writes. The subprocess is started with:
+//
+//	HF_HUB_CACHE=<dir>              models are read from the shared cache
+//	HF_HUB_OFFLINE=1                no network lookups, so no download locks
+//	HF_HUB_DISABLE_TELEMETRY=1      no telemetry writes
+//	HF_HOME=<job temp dir>/hf-home  token and other hub state go to a writable dir
+//
+// Pass an empty string to go back to the default, writable cache.
+func (m *MLXAdapter) SetReadOnlyModelCache(dir string) {
+	m.readOnlyCacheDir = dir
+}
+
+// WithOfflineCache runs the adapter offline with dir as HF_HOME: subprocesses
+// get HF_HUB_OFFLINE=1 and UV_OFFLINE=1, so nothing waits on the network, and
+// a model missing from the cache fails fast with ErrModelNotCached. Unlike
+// SetReadOnlyModelCache the cache stays writable. An empty dir goes back
+// online. It returns the adapter for chaining.
+func (m *MLXAdapter) WithOfflineCache(dir string) *MLXAdapter {
+	m.offlineCacheDir = dir
+	return m
+}
+
+// offline reports whether models must come from the local cache only
+func (m *MLXAdapter) offline() bool {
+	return m.readOnlyCacheDir != "" || m.offlineCacheDir != ""
+}
+
+// SetTranslator configures the translator used for target languages other
+// than English. Pass nil to disable.
+func (m *MLXAdapter) SetTranslator(t interfaces.Translator) {
+	m.translator = t
+}
+
+// SetSegmentAnalyzer configures an analyzer that labels each segment (e.g.
+// with sentiment) after transcription. Pass nil to disable.
+func (m *MLXAdapter) SetSegmentAnalyzer(a interfaces.SegmentAnalyzer) {
+	m.analyzer = a
+}
+
+// subprocessEnv builds the environment for the Python subprocess
+func (m *MLXAdapter) subprocessEnv(tempDir string) []string {
+	env := os.Environ()
+	if m.offlineCacheDir != "" {
+		env = append(env,
+			"HF_HOME="+m.offlineCacheDir,
+			"HF_HUB_OFFLINE=1",
+			"UV_OFFLINE=1",
+		)
+	}
+	if m.readOnlyCacheDir != "" {
+		env = append(env,
+			"HF_HUB_CACHE="+m.readOnlyCacheDir,
+			"HF_HUB_OFFLINE=1",
+			"HF_HUB_DISABLE_TELEMETRY=1",
+			"HF_HOME="+filepath.Join(tempDir, "hf-home"),
+		)
+	}
+	return env
+}
+
+// withRequestEnv appends a request's ProcessingContext.Env to env. The
+// variables come last, and exec keeps the last value of a repeated variable,
+// so they override the adapter's own settings.
+func withRequestEnv(env []string, vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+vars[key])
+	}
+	return env
+}
+
+// Available reports whether MLX can run here: it needs Apple Silicon under macOS
+func (m *MLXAdapter) Available() (bool, string) {
+	if runtime.GOOS != "darwin" {
+		return false, "MLX adapter is only supported on macOS"
+	}
+	if runtime.GOARCH != "arm64" {
+		return false, "MLX adapter requires an Apple Silicon (arm64) Mac"
 	}
+	return true, ""
 }
 
+// Ready reports the cached result of the last successful PrepareEnvironment.
+// It never spawns a subprocess or touches the filesystem, so it is safe to call
+// from frequent liveness/readiness probes.
+func (m *MLXAdapter) Ready() bool {
+	m.stateMu.RLock()
+	defer m.stateMu.RUnlock()
+	return m.initialized
+}
+
+// setReady updates the cached readiness state
+func (m *MLXAdapter) setReady(ready bool) {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	m.initialized = ready
+	// The environment may have been (re)installed
+	m.mlxWhisperVersion = ""
+}
+
+// IsReady checks if the adapter is ready to process jobs
+func (m *MLXAdapter) IsReady(ctx context.Context) bool {
+	if !m.Ready() {
+		return false
+	}
+	_, err := os.Stat(m.GetModelPath())
+	return err == nil
+}
+
+// GetSupportedModels returns the IDs from ListAvailableModels, cached for an
+// hour. It never waits for the hub: the bundled list is returned until the
+// first background refresh has finished.
 func (m *MLXAdapter) GetSupportedModels() []string {
-	return []string{"mlx-community/whisper-large-v3-mlx", "mlx-community/whisper-base-mlx"}
+	models := m.cachedModels()
+	ids := make([]string, len(models))
+	for i, model := range models {
+		ids[i] = model.ID
+	}
+	return ids
 }
 
+// PrepareEnvironment installs the Python environment. Cancelling ctx stops
+// the uv subprocesses and returns an error wrapping ctx.Err(); calling it
+// again resumes an interrupted setup.
 func (m *MLXAdapter) PrepareEnvironment(ctx context.Context) error {
-	// Only proceed if running on macOS
-	if runtime.GOOS != "darwin" {
-		return fmt.Errorf("MLX adapter is only supported on macOS")
+	if available, reason := m.Available(); !available {
+		return errors.New(reason)
+	}
+	if m.envPathErr != nil {
+		return m.envPathErr
+	}
+	if err := checkEnvPathWritable(m.envPath); err != nil {
+		return err
+	}
+	if err := checkUV(ctx, m.commandRunner()); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("MLX environment setup cancelled: %w", ctx.Err())
+		}
+		return err
 	}
 
 	mlxPath := filepath.Join(m.envPath, "MLX")
 
+	// Install the Python bridge (also refreshes it after an upgrade)
+	if _, err := m.installScripts(); err != nil {
+		return err
+	}
+	m.cleanupStaleTempDirsOnPrepare()
+
 	// Check if already ready
-	if CheckEnvironmentReady(mlxPath, "import mlx_whisper") {
-		m.initialized = true
+	if checkEnvironmentReady(ctx, m.commandRunner(), mlxPath, "import mlx_whisper") {
+		if err := m.warmUpModels(ctx); err != nil {
+			return err
+		}
+		m.setReady(true)
 		return nil
 	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("MLX environment setup cancelled: %w", err)
+	}
 
 	// Create directory
 	if err := os.MkdirAll(mlxPath, 0755); err != nil {
@@ -100,83 +749,702 @@ func (m *MLXAdapter) PrepareEnvironment(ctx context.Context) error {
 	// Check if pyproject.toml exists to avoid re-initializing
 	if _, err := os.Stat(filepath.Join(mlxPath, "pyproject.toml")); os.IsNotExist(err) {
 		// Initialize UV project with a specific name to avoid shadowing 'mlx' package
-		initCmd := exec.Command("uv", "init", "--name", "scriberr-mlx-wrapper")
-		initCmd.Dir = mlxPath
-		if out, err := initCmd.CombinedOutput(); err != nil {
+		initCmd := m.uvSetupCommand(ctx, mlxPath, "init", "--name", "scriberr-mlx-wrapper")
+		if out, err := m.commandCombinedOutput(ctx, initCmd); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("MLX environment setup cancelled: %w", ctx.Err())
+			}
 			return fmt.Errorf("uv init failed: %s: %w", string(out), err)
 		}
 	}
 
-	// Install dependencies
-	installCmd := exec.Command("uv", "add", "mlx-whisper", "ffmpeg-python")
-	installCmd.Dir = mlxPath
-	if out, err := installCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to install mlx-whisper: %s", string(out))
+	// Install dependencies. uv add is idempotent, so after an interrupted
+	// setup it is simply run again and finishes the install.
+	mlxWhisper := "mlx-whisper"
+	if m.mlxWhisperPin != "" {
+		mlxWhisper += "==" + m.mlxWhisperPin
+	}
+	installCmd := m.uvSetupCommand(ctx, mlxPath, "add", mlxWhisper, "ffmpeg-python", "whisper-normalizer")
+	if out, err := m.commandCombinedOutput(ctx, installCmd); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("MLX environment setup cancelled: %w", ctx.Err())
+		}
+		return fmt.Errorf("failed to install mlx-whisper: %s: %w", string(out), err)
 	}
 
-	m.initialized = true
+	if err := m.warmUpModels(ctx); err != nil {
+		return err
+	}
+	m.setReady(true)
 	return nil
 }
 
+// uvSetupCommand builds a uv command run in dir while setting up the
+// environment. It runs in its own process group so cancelling ctx also stops
+// the resolvers and builds uv starts.
+func (m *MLXAdapter) uvSetupCommand(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "uv", args...)
+	configureCmdSysProcAttr(cmd)
+	cmd.Cancel = func() error { return killProcessTree(cmd.Process) }
+	cmd.WaitDelay = 5 * time.Second
+	cmd.Dir = dir
+	cmd.Env = m.subprocessEnv(os.TempDir())
+	return cmd
+}
+
+// Transcribe transcribes input with mlx-whisper. If the subprocess fails after
+// decoding part of the audio, the segments decoded so far are returned as a
+// result with Partial set, together with an error wrapping ErrPartialResult
+// and the failure.
 func (m *MLXAdapter) Transcribe(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, error) {
+	return m.transcribeJob(ctx, input, params, procCtx, nil)
+}
+
+// transcribeJob runs a whole transcription job, delivering segments to stream
+// as they are decoded if it is set
+func (m *MLXAdapter) transcribeJob(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext, stream *segmentStream) (*interfaces.TranscriptResult, error) {
 	startTime := time.Now()
+
+	// Every run gets a job ID so logs, temp directories and the result can be
+	// correlated; a caller-supplied ID is kept as is
+	if procCtx.JobID == "" {
+		procCtx.JobID = uuid.New().String()
+	}
 	m.LogProcessingStart(input, procCtx)
 	defer func() { m.LogProcessingEnd(procCtx, time.Since(startTime), nil) }()
 
+	// Presets are expanded first so that schema defaults filled in by
+	// validation do not mask them
+	params, err := m.applyQualityPreset(params)
+	if err == nil {
+		err = m.checkModelAllowed(params)
+	}
+	if err == nil {
+		params, err = m.ValidateAndApplyDefaults(params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.withMaxDuration(ctx)
+	defer cancel()
+
+	input, inputDir, err := m.prepareInput(ctx, input, params, procCtx)
+	defer m.CleanupTempDirectory(inputDir)
+	if err == nil {
+		input, err = m.probeInput(ctx, input, procCtx)
+	}
+	if err != nil {
+		return nil, timeoutError(ctx, err, time.Since(startTime), m.GetStringParameter(params, "model"))
+	}
+
+	run := &mlxRun{jobID: procCtx.JobID, stream: stream}
+	if m.GetBoolParameter(params, "debug_dump") {
+		run.dump = m.newDebugDump(input, procCtx, startTime)
+	}
+	progress, err := openProgress(procCtx)
+	if err != nil {
+		logger.Warn("Failed to open progress output", "job_id", procCtx.JobID, "error", err)
+	} else if progress != nil {
+		defer progress.Close()
+		run.progress = progress
+	}
+
+	run.emit("started", input.FilePath)
+	var result *interfaces.TranscriptResult
+	cacheKey := m.resultCacheKey(input, params, procCtx.Env)
+	if procCtx.DryRun {
+		// Nothing is transcribed, so the cache is neither read nor written
+		result, err = m.transcribe(ctx, input, params, procCtx, run)
+	} else if cached, ok := m.loadCachedResult(cacheKey); ok {
+		logger.Info("Reusing cached MLX result", "job_id", procCtx.JobID, "audio_file", input.FilePath)
+		cached.JobID = procCtx.JobID
+		// The run that produced it may have kept a temp directory
+		delete(cached.Metadata, "temp_dir")
+		result, err = cached, nil
+	} else if result, err = m.transcribeWithRetry(ctx, input, params, procCtx, run); err == nil {
+		if err := m.saveCachedResult(cacheKey, result); err != nil {
+			logger.Warn("Failed to cache MLX result", "job_id", procCtx.JobID, "error", err)
+		}
+	}
+	err = timeoutError(ctx, err, time.Since(startTime), m.GetStringParameter(params, "model"))
+	if err != nil {
+		run.emit("failed", err.Error())
+	} else {
+		result.ProcessingTime = time.Since(startTime)
+		result.Metrics = transcriptMetrics(result, input, result.ProcessingTime, m.GetFloatParameter(params, "time_offset"))
+		run.emit("completed", "")
+	}
+
+	if run.dump != nil {
+		run.dump.finish(err)
+		m.writeDebugDump(procCtx, input.FilePath, run.dump)
+	}
+	return result, err
+}
+
+// transcribe runs one transcription, reporting to the optional outputs in run.
+// If run.batch is set, every item is transcribed in the same subprocess,
+// outcomes are stored in the items and transcribe returns nil, nil.
+func (m *MLXAdapter) transcribe(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext, run *mlxRun) (result *interfaces.TranscriptResult, err error) {
 	if err := m.ValidateAudioInput(input); err != nil {
 		return nil, err
 	}
 
+	params, err = m.applyQualityPreset(params)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.validateModelTask(params); err != nil {
+		return nil, err
+	}
+	if run.dump != nil {
+		run.dump.Model = m.GetStringParameter(params, "model")
+		run.dump.Params = m.resolvedParams(params)
+	}
+
+	language := normalizeLanguage(m.GetStringParameter(params, "language"))
+	if !m.stringInSlice(language, m.GetCapabilities().SupportedLanguages) {
+		return nil, fmt.Errorf("unsupported language %q: supported languages are %v", m.GetStringParameter(params, "language"), m.GetCapabilities().SupportedLanguages)
+	}
+	task := m.GetStringParameter(params, "task")
+	if task != "transcribe" && task != "translate" {
+		return nil, fmt.Errorf("unsupported task %q: must be transcribe or translate", task)
+	}
+	quantization := m.GetStringParameter(params, "quantization")
+	if !m.stringInSlice(quantization, []string{"4bit", "8bit", "none"}) {
+		return nil, fmt.Errorf("unsupported quantization %q: must be 4bit, 8bit or none", quantization)
+	}
+
+	noSpeechThreshold := m.GetFloatParameter(params, "no_speech_threshold")
+	if noSpeechThreshold < 0 || noSpeechThreshold > 1 {
+		return nil, fmt.Errorf("invalid no_speech_threshold %g: must be between 0 and 1", noSpeechThreshold)
+	}
+	if err := m.validateDecodingStrategy(params); err != nil {
+		return nil, err
+	}
+	if n := m.GetIntParameter(params, "repetition_threshold"); n == 1 {
+		return nil, fmt.Errorf("invalid repetition_threshold %d: must be 0 or at least 2", n)
+	}
+	compressionRatioThreshold := m.GetFloatParameter(params, "compression_ratio_threshold")
+	if compressionRatioThreshold < 1 {
+		return nil, fmt.Errorf("invalid compression_ratio_threshold %g: must be at least 1.0", compressionRatioThreshold)
+	}
+
+	// Resolve output options before spending time in the subprocess
+	if _, err := timestampDecimals(m.GetStringParameter(params, "timestamp_precision")); err != nil {
+		return nil, err
+	}
+	if value, ok := params["decoding_options"]; ok && value != nil {
+		if _, err := m.convertToMap(value); err != nil {
+			return nil, fmt.Errorf("invalid decoding_options: %w", err)
+		}
+	}
+	extraOptions := m.GetMapParameter(params, "extra_options")
+	if value, ok := params["extra_options"]; ok && value != nil {
+		if _, err := m.convertToMap(value); err != nil {
+			return nil, fmt.Errorf("invalid extra_options: %w", err)
+		}
+		for _, key := range reservedExtraOptions {
+			if _, ok := extraOptions[key]; ok {
+				return nil, fmt.Errorf("invalid extra_options: %q is set by the adapter", key)
+			}
+		}
+	}
+	suppressTokens := m.GetStringParameter(params, "suppress_tokens")
+	if _, err := parseTokenIDs(suppressTokens); err != nil {
+		return nil, fmt.Errorf("invalid suppress_tokens: %w", err)
+	}
+	if err := interfaces.ValidateOutputNameTemplate(procCtx.OutputNameTemplate); err != nil {
+		return nil, err
+	}
+	if m.GetBoolParameter(params, "overlap_voting") &&
+		m.GetFloatParameter(params, "voting_overlap") >= m.GetFloatParameter(params, "voting_window") {
+		return nil, fmt.Errorf("voting_overlap must be less than voting_window")
+	}
+	startFrom := m.GetFloatParameter(params, "start_from")
+	if startFrom < 0 {
+		return nil, fmt.Errorf("start_from must not be negative")
+	}
+	if input.Duration > 0 && startFrom >= input.Duration.Seconds() {
+		return nil, fmt.Errorf("start_from %.3fs is beyond the end of the audio (%s)", startFrom, input.Duration)
+	}
+	ranges := m.GetRangesParameter(params, "ranges")
+	if err := validateRanges(ranges, input.Duration.Seconds()); err != nil {
+		return nil, err
+	}
+	if len(ranges) > 0 && (startFrom > 0 || m.GetBoolParameter(params, "overlap_voting")) {
+		return nil, fmt.Errorf("ranges cannot be combined with start_from or overlap_voting")
+	}
+	chunkLength := m.GetFloatParameter(params, "chunk_length")
+	if chunkLength > 0 {
+		if chunkLength <= mlxChunkOverlap {
+			return nil, fmt.Errorf("chunk_length must be longer than the %gs overlap between chunks", mlxChunkOverlap)
+		}
+		if len(ranges) > 0 || startFrom > 0 || m.GetBoolParameter(params, "overlap_voting") {
+			return nil, fmt.Errorf("chunk_length cannot be combined with ranges, start_from or overlap_voting")
+		}
+		// The audio probe fills in the duration unless it was skipped
+		if input.Duration <= 0 {
+			return nil, fmt.Errorf("chunk_length needs the audio duration: set it on the input or let the audio probe run")
+		}
+	}
+	recordingStart, err := parseRecordingStart(m.GetStringParameter(params, "recording_start_time"))
+	if err != nil {
+		return nil, err
+	}
+	targetLanguage := strings.ToLower(m.GetStringParameter(params, "target_language"))
+	if targetLanguage != "" && targetLanguage != "en" && m.translator == nil {
+		return nil, fmt.Errorf("target_language %q requires a translator; Whisper can only translate to English", targetLanguage)
+	}
+
+	skipIfFresh := m.GetBoolParameter(params, "skip_if_fresh")
+	if skipIfFresh && run.batch == nil {
+		if cached, ok := m.loadFreshResult(input, params, procCtx); ok {
+			logger.Info("Reusing fresh MLX result", "job_id", procCtx.JobID, "audio_file", input.FilePath)
+			cached.JobID = procCtx.JobID
+			return cached, nil
+		}
+	}
+
 	tempDir, err := m.CreateTempDirectory(procCtx)
 	if err != nil {
 		return nil, err
 	}
-	defer m.CleanupTempDirectory(tempDir)
+	defer func() {
+		if !procCtx.KeepTemp && (!procCtx.KeepTempOnError || err == nil) {
+			m.CleanupTempDirectory(tempDir)
+			return
+		}
+		// CleanupStaleTempDirs still removes it once it is old enough
+		unlockTempDirectory(tempDir)
+		logger.Info("Keeping MLX temp directory", "job_id", procCtx.JobID, "dir", tempDir)
+		if err != nil {
+			err = fmt.Errorf("%w (temp directory kept at %s)", err, tempDir)
+		}
+		if result != nil {
+			if result.Metadata == nil {
+				result.Metadata = make(map[string]string)
+			}
+			result.Metadata["temp_dir"] = tempDir
+		}
+	}()
+
+	// The script is normally installed by PrepareEnvironment; this only
+	// writes it if it is missing or out of date
+	scriptPath, err := m.installScripts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to install script: %w", err)
+	}
 
-	// Create the Python script
-	scriptPath := filepath.Join(tempDir, "transcribe_mlx.py")
-	scriptContent := m.generatePythonScript()
-	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write script: %w", err)
+	audioPath := input.FilePath
+	if startFrom > 0 {
+		audioPath = filepath.Join(tempDir, "start_from.wav")
+		if err := m.extractAudio(ctx, input.FilePath, startFrom, 0, audioPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Chunked mode reuses the ranges manifest with overlapping chunks that
+	// are merged again after parsing
+	var chunks [][2]float64
+	if chunkLength > 0 {
+		chunks = chunkRanges(input.Duration.Seconds(), chunkLength, mlxChunkOverlap)
+		ranges = chunks
+	}
+
+	var manifestPath string
+	if len(ranges) > 0 {
+		if manifestPath, err = m.writeRangesManifest(ctx, input.FilePath, ranges, tempDir); err != nil {
+			return nil, err
+		}
 	}
 
 	modelName := m.GetStringParameter(params, "model")
-	outputJson := filepath.Join(tempDir, "output.json")
+	if m.offlineCacheDir != "" && !procCtx.DryRun {
+		if repo := offlineModelRepo(modelName, quantization); !m.modelCached(ctx, repo) {
+			return nil, fmt.Errorf("%w: %s", ErrModelNotCached, repo)
+		}
+	}
+	outputName, err := procCtx.OutputFileName(input.FilePath, "json", "output.json")
+	if err != nil {
+		return nil, err
+	}
+	outputJson := filepath.Join(tempDir, outputName)
+	var batchPath string
+	if run.batch != nil {
+		if batchPath, err = writeBatchManifest(run.batch, tempDir, procCtx); err != nil {
+			return nil, err
+		}
+	}
 
 	// Construct UV command
 	mlxPath := filepath.Join(m.envPath, "MLX")
-	cmd := exec.CommandContext(ctx, "uv", "run", "--project", mlxPath, "python", scriptPath,
-		"--audio", input.FilePath,
+	args := []string{"run", "--project", mlxPath, "python", scriptPath,
+		"--audio", audioPath,
 		"--model", modelName,
 		"--output", outputJson,
+		"--nan-handling", m.GetStringParameter(params, "nan_handling"),
+		"--control-dir", tempDir,
+		"--quantization", quantization,
+	}
+	if m.offline() {
+		args = append(args, "--local-files-only")
+	}
+	// target_language "en" is Whisper's translate task
+	if targetLanguage == "en" {
+		task = "translate"
+	}
+	args = append(args, "--task", task)
+	if language != "auto" {
+		args = append(args, "--language", language)
+	}
+	if !m.GetBoolParameter(params, "word_timestamps") {
+		args = append(args, "--no-word-timestamps")
+	}
+	if bestOf := m.GetIntParameter(params, "best_of"); bestOf > 0 {
+		args = append(args, "--best-of", strconv.Itoa(bestOf))
+	}
+	if m.GetParameterWithDefault(params, "suppress_tokens") != nil {
+		args = append(args, "--suppress-tokens", suppressTokens)
+	}
+	if m.GetParameterWithDefault(params, "suppress_blank") != nil {
+		args = append(args, "--suppress-blank", strconv.FormatBool(m.GetBoolParameter(params, "suppress_blank")))
+	}
+	args = append(args, "--condition-on-previous-text", strconv.FormatBool(m.GetBoolParameter(params, "condition_on_previous_text")))
+	seeded := m.GetParameterWithDefault(params, "seed") != nil
+	if seeded {
+		args = append(args, "--seed", strconv.Itoa(m.GetIntParameter(params, "seed")))
+	}
+	if temperatures := m.GetFloatListParameter(params, "temperature"); len(temperatures) > 0 {
+		schedule := make([]string, len(temperatures))
+		for i, t := range temperatures {
+			schedule[i] = strconv.FormatFloat(t, 'g', -1, 64)
+		}
+		args = append(args, "--temperature", strings.Join(schedule, ","))
+	}
+	args = append(args,
+		"--no-speech-threshold", strconv.FormatFloat(noSpeechThreshold, 'g', -1, 64),
+		"--compression-ratio-threshold", strconv.FormatFloat(compressionRatioThreshold, 'g', -1, 64),
 	)
+	// Prompts can be long, so they travel in a file rather than on the command line
+	if prompt := m.GetStringParameter(params, "initial_prompt"); prompt != "" {
+		promptPath := filepath.Join(tempDir, "initial_prompt.txt")
+		if err := os.WriteFile(promptPath, []byte(prompt), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write initial prompt: %w", err)
+		}
+		args = append(args, "--initial-prompt-file", promptPath)
+	}
+	if decodingOptions := m.GetMapParameter(params, "decoding_options"); len(decodingOptions) > 0 {
+		optionsPath := filepath.Join(tempDir, "decoding_options.json")
+		if err := writeJSONFile(optionsPath, decodingOptions); err != nil {
+			return nil, fmt.Errorf("failed to write decoding options: %w", err)
+		}
+		args = append(args, "--decoding-options", optionsPath)
+	}
+	if len(extraOptions) > 0 {
+		extraPath := filepath.Join(tempDir, "extra_options.json")
+		if err := writeJSONFile(extraPath, extraOptions); err != nil {
+			return nil, fmt.Errorf("failed to write extra options: %w", err)
+		}
+		args = append(args, "--extra-options", extraPath)
+	}
+	if manifestPath != "" {
+		args = append(args, "--ranges-manifest", manifestPath)
+	}
+	if batchPath != "" {
+		args = append(args, "--batch-manifest", batchPath)
+	}
+	if m.GetBoolParameter(params, "lightweight_turns") {
+		args = append(args, "--lightweight-turns")
+	}
+	if m.GetBoolParameter(params, "download_only_quantization") && m.readOnlyCacheDir == "" {
+		args = append(args, "--only-quantization", m.GetStringParameter(params, "quantization"))
+	}
+	if m.GetBoolParameter(params, "normalize_for_training") {
+		args = append(args, "--normalize")
+	}
+	if m.GetBoolParameter(params, "overlap_voting") {
+		args = append(args,
+			"--voting-window", fmt.Sprintf("%.2f", m.GetFloatParameter(params, "voting_window")),
+			"--voting-overlap", fmt.Sprintf("%.2f", m.GetFloatParameter(params, "voting_overlap")),
+		)
+	}
 
-	// Set standard output for logging
-	logFile, _ := os.Create(filepath.Join(procCtx.OutputDirectory, "mlx_transcription.log"))
-	cmd.Stdout = logFile
-	cmd.Stderr = logFile
+	if run.progress != nil {
+		// The progress channel is inherited as the first extra file (fd 3)
+		args = append(args, "--progress-fd", "3", "--job-id", procCtx.JobID)
+	}
+	if procCtx.ProgressFn != nil {
+		args = append(args, "--progress-lines")
+	}
+	// Segments are streamed live only when nothing after the subprocess
+	// rewrites the segment list; otherwise TranscribeStream sends them from
+	// the result
+	liveSegments := run.stream != nil && len(chunks) == 0 && m.analyzer == nil &&
+		m.GetFloatParameter(params, "min_segment_duration") == 0 &&
+		(targetLanguage == "" || targetLanguage == "en")
+	if liveSegments {
+		args = append(args, "--stream-segments")
+		run.stream.restart()
+	}
+	partialPath := filepath.Join(tempDir, "segments.partial")
+	if run.batch == nil {
+		args = append(args, "--partial-output", partialPath)
+	}
+	if run.dump != nil {
+		run.dump.Command = append([]string{"uv"}, args...)
+	}
+	if procCtx.DryRun {
+		return dryRunResult(append([]string{"uv"}, args...), scriptPath, modelName, quantization, procCtx.JobID), nil
+	}
+
+	// uv starts python as a child; on cancellation kill the whole process
+	// group so the model does not linger in memory
+	cmd := exec.CommandContext(ctx, "uv", args...)
+	configureCmdSysProcAttr(cmd)
+	cmd.Cancel = func() error { return killProcessTree(cmd.Process) }
+	cmd.WaitDelay = 5 * time.Second
+	if run.progress != nil {
+		cmd.ExtraFiles = []*os.File{run.progress}
+	}
+	cmd.Env = m.subprocessEnv(tempDir)
+	if seeded {
+		// Hash randomization is fixed at interpreter start, so it cannot be
+		// seeded from the script
+		cmd.Env = append(cmd.Env, "PYTHONHASHSEED="+strconv.Itoa(m.GetIntParameter(params, "seed")))
+	}
+	cmd.Env = withRequestEnv(cmd.Env, procCtx.Env)
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("MLX execution failed: %w", err)
+	// Set standard output for logging. With an output sink the logs are
+	// written to the temp directory and handed to the sink afterwards.
+	logDir := procCtx.OutputDirectory
+	if procCtx.OutputSink != nil {
+		logDir = tempDir
 	}
+	// stderr is also kept in memory to diagnose failures
+	stderrTail := &tailBuffer{max: 8192}
+	cmd.Stderr = stderrTail
+	stdout, stderr, logFiles, err := m.openSubprocessLogs(procCtx, logDir, input.FilePath, m.GetBoolParameter(params, "split_streams"))
+	if err != nil {
+		// Run without a log rather than fail the job
+		logger.Warn("Failed to create MLX log file", "job_id", procCtx.JobID, "error", err)
+		cmd.Stdout = io.Discard
+	} else {
+		defer closeFiles(logFiles)
+		cmd.Stdout = stdout
+		cmd.Stderr = io.MultiWriter(stderr, stderrTail)
+	}
+	// Turn stdout into structured events as it arrives, still logging it raw
+	output := &outputLineWriter{
+		out:      cmd.Stdout,
+		progress: procCtx.ProgressFn,
+		log: func(level, msg string, fields map[string]any) {
+			fields["job_id"] = procCtx.JobID
+			m.log(level, msg, fields)
+		},
+	}
+	if liveSegments {
+		output.segment = func(data string) {
+			var raw mlxSegment
+			if err := json.Unmarshal([]byte(data), &raw); err != nil {
+				logger.Warn("Malformed MLX segment line", "job_id", procCtx.JobID, "error", err)
+				return
+			}
+			if seg, ok := m.finishStreamSegment(raw, params, language, recordingStart); ok {
+				run.stream.send(seg)
+			}
+		}
+	}
+	cmd.Stdout = output
 
-	return m.parseResult(outputJson, params)
+	logger.Info("Executing MLX command", "job_id", procCtx.JobID, "model", modelName)
+
+	release, err := m.acquireTranscribeSlot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("MLX transcription cancelled while waiting for a slot: %w", err)
+	}
+	defer release()
+
+	m.trackJob(procCtx.JobID, tempDir)
+	err = m.runCommand(ctx, cmd)
+	m.untrackJob(procCtx.JobID)
+	if procCtx.OutputSink != nil {
+		closeFiles(logFiles)
+		putLogFiles(procCtx, logFiles)
+	}
+	if err != nil {
+		err = m.classifyRunError(ctx, err, stderrTail.String(), input.FilePath, modelName, procCtx.JobID)
+		if partial := m.recoverPartialResult(partialPath, params, chunks); partial != nil {
+			partial.JobID = procCtx.JobID
+			logger.Warn("Recovered partial MLX result", "job_id", procCtx.JobID, "segments", len(partial.Segments))
+			return partial, fmt.Errorf("%w: %w", ErrPartialResult, err)
+		}
+		return nil, err
+	}
+
+	// finish turns one output file of the subprocess into a result
+	finish := func(outputPath, outputName, jobID string, input interfaces.AudioInput) (*interfaces.TranscriptResult, error) {
+		result, err := m.parseResult(outputPath, params, chunks)
+		if err != nil {
+			return nil, err
+		}
+		result.JobID = jobID
+		if !recordingStart.IsZero() {
+			applyWallClock(result, recordingStart)
+		}
+
+		if targetLanguage != "" && targetLanguage != "en" {
+			if err := m.translateResult(ctx, result, targetLanguage); err != nil {
+				return nil, err
+			}
+		}
+
+		if m.analyzer != nil {
+			if err := m.analyzeSegments(ctx, result, input.FilePath); err != nil {
+				return nil, err
+			}
+		}
+
+		// With an output sink the saved result is the one put below
+		if skipIfFresh {
+			if err := m.saveFreshResult(result, input, params, procCtx); err != nil {
+				logger.Warn("Failed to save MLX result for reuse", "job_id", jobID, "error", err)
+			}
+		}
+
+		if procCtx.OutputSink != nil {
+			if err := putResult(procCtx, outputName, result); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	if run.batch != nil {
+		for _, item := range run.batch {
+			if err := batchOutputError(item.output, item.input.FilePath); err != nil {
+				item.err = err
+				continue
+			}
+			item.result, item.err = finish(item.output, item.outputName, item.jobID, item.input)
+		}
+		return nil, nil
+	}
+	return finish(outputJson, outputName, procCtx.JobID, input)
+}
+
+// analyzeSegments runs the configured SegmentAnalyzer over every segment. A
+// failure on one segment is logged and leaves that segment unlabelled.
+func (m *MLXAdapter) analyzeSegments(ctx context.Context, result *interfaces.TranscriptResult, audioPath string) error {
+	for i := range result.Segments {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		seg := &result.Segments[i]
+		labels, err := m.analyzer.AnalyzeSegment(ctx, interfaces.SegmentAnalysisInput{
+			Text:      seg.Text,
+			Start:     seg.Start,
+			End:       seg.End,
+			Language:  result.Language,
+			AudioPath: audioPath,
+		})
+		if err != nil {
+			logger.Warn("Segment analysis failed", "job_id", result.JobID, "segment", i, "error", err)
+			continue
+		}
+		if len(labels) > 0 {
+			seg.Labels = labels
+		}
+	}
+	return nil
+}
+
+// openSubprocessLogs opens the log file(s) for the Python subprocess in dir.
+// With split set, stdout and stderr go to separate files so structured stdout
+// can be parsed without tracebacks mixed in; otherwise both share one file.
+func (m *MLXAdapter) openSubprocessLogs(procCtx interfaces.ProcessingContext, dir, inputPath string, split bool) (stdout, stderr io.Writer, files []*os.File, err error) {
+	create := func(ext, defaultName string) (*os.File, error) {
+		name, err := procCtx.OutputFileName(inputPath, ext, defaultName)
+		if err != nil {
+			return nil, err
+		}
+		return os.Create(filepath.Join(dir, name))
+	}
+
+	// Default names carry the job ID so concurrent jobs sharing an output
+	// directory do not overwrite each other's logs
+	prefix := "mlx_transcription-" + procCtx.JobID
+	if !split {
+		logFile, err := create("log", prefix+".log")
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return logFile, logFile, []*os.File{logFile}, nil
+	}
+
+	stdoutFile, err := create("stdout.log", prefix+".stdout.log")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stderrFile, err := create("stderr.log", prefix+".stderr.log")
+	if err != nil {
+		stdoutFile.Close()
+		return nil, nil, nil, err
+	}
+	return stdoutFile, stderrFile, []*os.File{stdoutFile, stderrFile}, nil
 }
 
-func (m *MLXAdapter) parseResult(jsonPath string, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+// translateResult translates each segment into targetLanguage with the
+// configured translator. Segment timings are preserved; word timings keep
+// referring to the source-language speech.
+func (m *MLXAdapter) translateResult(ctx context.Context, result *interfaces.TranscriptResult, targetLanguage string) error {
+	if strings.EqualFold(result.Language, targetLanguage) {
+		return nil
+	}
+
+	texts := make([]string, 0, len(result.Segments))
+	for i := range result.Segments {
+		seg := &result.Segments[i]
+		if seg.Text == "" {
+			continue
+		}
+		translated, err := m.translator.Translate(ctx, seg.Text, result.Language, targetLanguage)
+		if err != nil {
+			return fmt.Errorf("failed to translate segment %d to %s: %w", i, targetLanguage, err)
+		}
+		seg.Text = strings.TrimSpace(translated)
+		texts = append(texts, seg.Text)
+	}
+	result.Text = strings.Join(texts, " ")
+
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]string)
+	}
+	result.Metadata["target_language"] = targetLanguage
+	return nil
+}
+
+// parseResult reads an output file of the Python bridge into a result and
+// applies the post-processing params ask for. chunks are the overlapping
+// chunks of a chunked run, whose duplicates are merged away; nil otherwise.
+func (m *MLXAdapter) parseResult(jsonPath string, params map[string]interface{}, chunks [][2]float64) (*interfaces.TranscriptResult, error) {
 	data, err := os.ReadFile(jsonPath)
 	if err != nil {
 		return nil, err
 	}
 
 	var mlxOutput struct {
-		Text     string `json:"text"`
-		Segments []struct {
-			Start float64 `json:"start"`
-			End   float64 `json:"end"`
-			Text  string  `json:"text"`
-		} `json:"segments"`
-		Language string `json:"language"`
+		Text              string                    `json:"text"`
+		NormalizedText    string                    `json:"normalized_text"`
+		Segments          []mlxSegment              `json:"segments"`
+		Language          string                    `json:"language"`
+		TurnBoundaries    []interfaces.TurnBoundary `json:"turn_boundaries"`
+		OverlapConflicts  *int                      `json:"overlap_conflicts"`
+		MLXWhisperVersion string                    `json:"mlx_whisper_version"`
+		Model             string                    `json:"model"`
+		ModelDetails      map[string]string         `json:"model_details"`
 	}
 
 	if err := json.Unmarshal(data, &mlxOutput); err != nil {
@@ -189,68 +1457,285 @@ func (m *MLXAdapter) parseResult(jsonPath string, params map[string]interface{})
 		Language:  mlxOutput.Language,
 		ModelUsed: m.GetStringParameter(params, "model"),
 		Segments:  make([]interfaces.TranscriptSegment, len(mlxOutput.Segments)),
+
+		NormalizedText: mlxOutput.NormalizedText,
+		TurnBoundaries: mlxOutput.TurnBoundaries,
+		ModelDetails:   mlxOutput.ModelDetails,
+
+		Metadata: map[string]string{
+			"adapter_version": m.GetCapabilities().Version,
+		},
+	}
+	if mlxOutput.Model != "" {
+		result.ModelUsed = mlxOutput.Model
+	}
+	if mlxOutput.MLXWhisperVersion != "" {
+		result.Metadata["mlx_whisper_version"] = mlxOutput.MLXWhisperVersion
+	}
+	if mlxOutput.OverlapConflicts != nil {
+		result.Metadata["overlap_conflicts_resolved"] = strconv.Itoa(*mlxOutput.OverlapConflicts)
 	}
 
+	repaired := 0
 	for i, seg := range mlxOutput.Segments {
-		result.Segments[i] = interfaces.TranscriptSegment{
-			Start: seg.Start,
-			End:   seg.End,
-			Text:  strings.TrimSpace(seg.Text),
+		var malformed bool
+		result.Segments[i], malformed = m.convertSegment(seg, params)
+		if malformed {
+			repaired++
+		}
+	}
+	if repaired > 0 {
+		result.Metadata["word_timings_repaired"] = strconv.Itoa(repaired)
+		logger.Warn("Repaired malformed MLX word timings", "segments", repaired,
+			"mode", m.GetStringParameter(params, "malformed_word_timings"))
+	}
+
+	// Chunks are merged first, on the raw decoder output: the steps below
+	// would otherwise see the overlaps twice (e.g. collapsing them as
+	// repetitions), and the merge needs the unshifted timestamps
+	if len(chunks) > 0 {
+		mergeChunks(result, chunks)
+	}
+
+	if threshold := m.GetFloatParameter(params, "word_confidence_threshold"); threshold > 0 {
+		var trimmed bool
+		result.Segments, trimmed = trimLowConfidenceTails(result.Segments, threshold, result.Language)
+		if trimmed {
+			result.Text = joinSegmentText(result.Segments, result.Language)
 		}
 	}
 
+	if minRun := m.GetIntParameter(params, "repetition_threshold"); minRun > 1 {
+		var collapsed int
+		result.Segments, collapsed = collapseRepetitions(result.Segments, minRun, m.GetFloatParameter(params, "no_speech_threshold"))
+		result.Metadata["repetitions_collapsed"] = strconv.Itoa(collapsed)
+		if collapsed > 0 {
+			result.Text = joinSegmentText(result.Segments, result.Language)
+		}
+	}
+
+	if minDuration := m.GetFloatParameter(params, "min_segment_duration"); minDuration > 0 {
+		result.Segments = mergeShortSegments(result.Segments, minDuration, m.GetFloatParameter(params, "merge_max_gap"), result.Language)
+	}
+
+	if offset := m.GetFloatParameter(params, "start_from"); offset > 0 {
+		shiftTimestamps(result, offset)
+	}
+	if offset := m.GetFloatParameter(params, "time_offset"); offset > 0 {
+		shiftTimestamps(result, offset)
+	}
+
+	switch m.GetStringParameter(params, "text_from_segments") {
+	case "space":
+		result.Text = joinSegmentText(result.Segments, "")
+	case "language_aware_join":
+		result.Text = joinSegmentText(result.Segments, result.Language)
+	}
+
+	decimals, err := timestampDecimals(m.GetStringParameter(params, "timestamp_precision"))
+	if err != nil {
+		return nil, err
+	}
+	roundTimestamps(result, decimals)
+	flattenWords(result)
+
 	return result, nil
 }
 
-// Helper: Python script to bridge MLX and our JSON format
-// We include clean_obj to handle NaN/Infinity values which crash Go's JSON parser
+// mlxSegment is a segment as written by the Python bridge
+type mlxSegment struct {
+	Start            float64  `json:"start"`
+	End              float64  `json:"end"`
+	Text             string   `json:"text"`
+	NormalizedText   string   `json:"normalized_text"`
+	RangeIndex       *int     `json:"range_index"`
+	AvgLogProb       *float64 `json:"avg_logprob"`
+	NoSpeechProb     *float64 `json:"no_speech_prob"`
+	CompressionRatio *float64 `json:"compression_ratio"`
+	Words            []struct {
+		Word        string   `json:"word"`
+		Start       float64  `json:"start"`
+		End         float64  `json:"end"`
+		Probability *float64 `json:"probability"`
+	} `json:"words"`
+}
+
+// convertSegment converts one bridge segment, repairing word timings and
+// adding character timings as params ask. It reports whether malformed word
+// timings were repaired.
+func (m *MLXAdapter) convertSegment(seg mlxSegment, params map[string]interface{}) (interfaces.TranscriptSegment, bool) {
+	converted := interfaces.TranscriptSegment{
+		Start:            seg.Start,
+		End:              seg.End,
+		Text:             strings.TrimSpace(seg.Text),
+		AvgLogProb:       seg.AvgLogProb,
+		NoSpeechProb:     seg.NoSpeechProb,
+		CompressionRatio: seg.CompressionRatio,
+		NormalizedText:   seg.NormalizedText,
+		RangeIndex:       seg.RangeIndex,
+	}
+	if len(seg.Words) == 0 {
+		return converted, false
+	}
+
+	words := make([]interfaces.WordTiming, len(seg.Words))
+	for j, w := range seg.Words {
+		words[j] = interfaces.WordTiming{
+			Start:       w.Start,
+			End:         w.End,
+			Word:        strings.TrimSpace(w.Word),
+			Probability: w.Probability,
+		}
+		if w.Probability != nil {
+			words[j].Score = *w.Probability
+		}
+	}
+	malformed := false
+	if m.GetBoolParameter(params, "repair_word_timings") {
+		if m.GetStringParameter(params, "malformed_word_timings") != "drop" {
+			malformed = repairWordTimings(converted, words)
+		} else if !wordTimingsValid(converted, words) {
+			// Keep only the segment-level timing
+			return converted, true
+		}
+	}
+	if m.GetBoolParameter(params, "char_timestamps") {
+		for j := range words {
+			words[j].Chars = interpolateCharTimings(words[j])
+		}
+	}
+	converted.Words = words
+	return converted, malformed
+}
+
+// languageNames maps English language names to the codes Whisper uses
+var languageNames = map[string]string{
+	"english":    "en",
+	"spanish":    "es",
+	"french":     "fr",
+	"german":     "de",
+	"italian":    "it",
+	"portuguese": "pt",
+	"dutch":      "nl",
+	"japanese":   "ja",
+	"chinese":    "zh",
+	"mandarin":   "zh",
+	"korean":     "ko",
+}
+
+// validateDecodingStrategy checks best_of (kept positive by the schema)
+// against the temperature schedule: Whisper samples best_of candidates only at
+// temperatures above 0, so the schedule needs one for it to have any effect.
+// A seed forces temperature 0.
+func (m *MLXAdapter) validateDecodingStrategy(params map[string]interface{}) error {
+	bestOf := m.GetIntParameter(params, "best_of")
+
+	temperatures := m.GetFloatListParameter(params, "temperature")
+	if m.GetParameterWithDefault(params, "seed") != nil {
+		temperatures = []float64{0}
+	}
+	if len(temperatures) == 0 {
+		// Whisper's default schedule samples after the first attempt
+		return nil
+	}
+	sampled := false
+	for _, t := range temperatures {
+		if t != 0 {
+			sampled = true
+		}
+	}
+	if bestOf > 0 && !sampled {
+		return fmt.Errorf("best_of %d needs a temperature above 0 (and no seed): it is not used with greedy decoding", bestOf)
+	}
+	return nil
+}
+
+// normalizeLanguage turns a language as callers write it ("EN", "en-US",
+// "pt_BR", "English") into a lowercase ISO 639-1 code. Empty means "auto".
+// The result is not validated.
+func normalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return "auto"
+	}
+	if code, ok := languageNames[language]; ok {
+		return code
+	}
+	// Drop BCP 47 script and region subtags
+	base, _, _ := strings.Cut(strings.ReplaceAll(language, "_", "-"), "-")
+	return base
+}
+
+// reservedExtraOptions name the input, model and output of a run, which
+// extra_options may not replace
+var reservedExtraOptions = []string{"audio", "path_or_hf_repo", "model", "output"}
+
+// parseTokenIDs parses a comma-separated list of token IDs. Blank entries are
+// ignored, so an empty list is valid.
+func parseTokenIDs(list string) ([]int, error) {
+	var ids []int
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a token ID", field)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// writeJSONFile marshals v to path
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// mlxTranscribeScript is the Python bridge that runs mlx_whisper. It imports
+// mlx_quantization.py, which is installed next to it.
+//
+//go:embed transcribe_mlx.py
+var mlxTranscribeScript string
+
+// generatePythonScript returns the transcription script
 func (m *MLXAdapter) generatePythonScript() string {
-	return `
-import argparse
-import json
-import mlx_whisper
-import math
-
-def clean_obj(obj):
-    if isinstance(obj, float):
-        if math.isnan(obj) or math.isinf(obj):
-            return None
-        return obj
-    elif isinstance(obj, dict):
-        return {k: clean_obj(v) for k, v in obj.items()}
-    elif isinstance(obj, list):
-        return [clean_obj(v) for v in obj]
-    return obj
-
-def main():
-    parser = argparse.ArgumentParser()
-    parser.add_argument("--audio", required=True)
-    parser.add_argument("--model", required=True)
-    parser.add_argument("--output", required=True)
-    args = parser.parse_args()
-
-    print(f"Loading model {args.model}...")
-    
-    # Transcribe
-    result = mlx_whisper.transcribe(
-        args.audio, 
-        path_or_hf_repo=args.model,
-        word_timestamps=True
-    )
-
-    # Clean NaNs/Infs which cause JSON errors in Go/other parsers
-    result = clean_obj(result)
-
-    # Save to JSON
-    with open(args.output, "w") as f:
-        json.dump(result, f, indent=2)
-
-if __name__ == "__main__":
-    main()
-`
+	return mlxTranscribeScript
+}
+
+// installScripts writes the Python bridge into the MLX environment and
+// returns the path of the transcription script. Files that are already up to
+// date are left alone, so this is cheap to call before every run.
+func (m *MLXAdapter) installScripts() (string, error) {
+	mlxPath := filepath.Join(m.envPath, "MLX")
+	if err := os.MkdirAll(mlxPath, 0755); err != nil {
+		return "", err
+	}
+
+	files := map[string]string{
+		"transcribe_mlx.py":   m.generatePythonScript(),
+		"mlx_quantization.py": mlxQuantizationDownloadPy,
+	}
+	for name, content := range files {
+		path := filepath.Join(mlxPath, name)
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, []byte(content)) {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return filepath.Join(mlxPath, "transcribe_mlx.py"), nil
 }
 
 // Auto-register
 func init() {
-	registry.RegisterTranscriptionAdapter("mlx_whisper", NewMLXAdapter("./data/mlx-env"))
+	registerAutoAdapter("mlx_whisper", "mlx-env", func(envPath string) interfaces.TranscriptionAdapter {
+		return NewMLXAdapterWithVersion(envPath, PinnedMLXWhisperVersion)
+	})
 }
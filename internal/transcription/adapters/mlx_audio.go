@@ -0,0 +1,277 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+)
+
+// ErrAudioDecode is returned when ffmpeg cannot decode the input, e.g. because
+// the file is corrupt, truncated or not audio. Retrying will not help; the
+// file has to be replaced. The error message names the file.
+var ErrAudioDecode = errors.New("audio could not be decoded")
+
+// audioDecodeMarkers are substrings of ffmpeg errors, and of the error
+// mlx_whisper raises when ffmpeg fails, that mean the input is unreadable
+var audioDecodeMarkers = []string{
+	"failed to load audio",
+	"invalid data found when processing input",
+	"moov atom not found",
+	"error while decoding",
+	"could not find codec parameters",
+	"does not contain any stream",
+	"header missing",
+	"partial file",
+}
+
+// isAudioDecodeError reports whether subprocess output shows a decode failure
+func isAudioDecodeError(output string) bool {
+	output = strings.ToLower(output)
+	for _, marker := range audioDecodeMarkers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareInput spools streamed input and, with transcode set, converts
+// unsupported formats to WAV. This happens once per job so OOM retries reuse
+// the result; the files go in their own directory (returned for cleanup, ""
+// if none was needed) because each attempt removes its temp directory.
+func (m *MLXAdapter) prepareInput(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext) (interfaces.AudioInput, string, error) {
+	format := input.Format
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(input.FilePath), ".")
+	}
+	streamed := input.FilePath == "" && input.Reader != nil
+	transcode := m.GetBoolParameter(params, "transcode") &&
+		!m.stringInSlice(strings.ToLower(format), m.GetCapabilities().SupportedFormats)
+	if !streamed && !transcode {
+		return input, "", nil
+	}
+
+	inputCtx := procCtx
+	inputCtx.JobID += "-input"
+	dir, err := m.CreateTempDirectory(inputCtx)
+	if err != nil {
+		return input, "", err
+	}
+	if input, err = m.SpoolAudioInput(input, dir); err != nil {
+		return input, dir, err
+	}
+	if !transcode {
+		return input, dir, nil
+	}
+
+	wavPath := filepath.Join(dir, "transcoded.wav")
+	if err := m.transcodeAudio(ctx, input.FilePath, wavPath); err != nil {
+		return input, dir, err
+	}
+	info, err := os.Stat(wavPath)
+	if err != nil {
+		return input, dir, fmt.Errorf("failed to stat transcoded audio: %w", err)
+	}
+	input.FilePath = wavPath
+	input.Format = "wav"
+	input.Size = info.Size()
+	return input, dir, nil
+}
+
+// audioProbe is what ffprobe found out about an input file
+type audioProbe struct {
+	duration time.Duration // 0 if ffprobe could not tell
+	err      error
+}
+
+// maxCachedProbes bounds the probe cache; it is simply emptied when full
+const maxCachedProbes = 256
+
+// probeInput checks with ffprobe that input has an audio stream and is not
+// empty, which catches e.g. videos or zero-sample files renamed to .wav. An
+// unknown input duration is filled in from the probe. Results are cached per
+// file version. The check is skipped if procCtx.SkipAudioProbe is set or
+// ffprobe is not installed.
+func (m *MLXAdapter) probeInput(ctx context.Context, input interfaces.AudioInput, procCtx interfaces.ProcessingContext) (interfaces.AudioInput, error) {
+	if procCtx.SkipAudioProbe || input.FilePath == "" {
+		return input, nil
+	}
+	info, err := os.Stat(input.FilePath)
+	if err != nil {
+		return input, fmt.Errorf("failed to stat audio file: %w", err)
+	}
+
+	key := fmt.Sprintf("%s|%d|%d", input.FilePath, info.Size(), info.ModTime().UnixNano())
+	m.probeMu.Lock()
+	probe, ok := m.probes[key]
+	m.probeMu.Unlock()
+	if !ok {
+		probe = m.probeAudio(ctx, input.FilePath)
+		if ctx.Err() != nil {
+			return input, ctx.Err()
+		}
+		if errors.Is(probe.err, exec.ErrNotFound) {
+			logger.Debug("ffprobe not found, skipping audio probe", "job_id", procCtx.JobID)
+			return input, nil
+		}
+		m.probeMu.Lock()
+		if m.probes == nil || len(m.probes) >= maxCachedProbes {
+			m.probes = make(map[string]audioProbe)
+		}
+		m.probes[key] = probe
+		m.probeMu.Unlock()
+	}
+
+	if probe.err != nil {
+		return input, probe.err
+	}
+	if input.Duration <= 0 {
+		input.Duration = probe.duration
+	}
+	return input, nil
+}
+
+// probeAudio runs ffprobe on path. A file ffprobe cannot read, without an
+// audio stream, or of zero duration is an ErrAudioDecode. A missing ffprobe
+// is reported as exec.ErrNotFound.
+func (m *MLXAdapter) probeAudio(ctx context.Context, path string) audioProbe {
+	var stderr strings.Builder
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=codec_type:format=duration",
+		"-of", "json", path)
+	cmd.Stderr = &stderr
+	out, err := m.commandOutput(ctx, cmd)
+	if errors.Is(err, exec.ErrNotFound) {
+		return audioProbe{err: err}
+	}
+	if err != nil {
+		return audioProbe{err: fmt.Errorf("%w: %s: ffprobe cannot read it: %s", ErrAudioDecode, path, strings.TrimSpace(stderr.String()))}
+	}
+
+	var probed struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(out, &probed); err != nil {
+		return audioProbe{err: fmt.Errorf("failed to parse ffprobe output for %s: %w", path, err)}
+	}
+	if len(probed.Streams) == 0 {
+		return audioProbe{err: fmt.Errorf("%w: %s has no audio stream", ErrAudioDecode, path)}
+	}
+	// Some containers do not record a duration ("N/A"); those are accepted
+	seconds, err := strconv.ParseFloat(probed.Format.Duration, 64)
+	if err != nil {
+		return audioProbe{}
+	}
+	if seconds <= 0 {
+		return audioProbe{err: fmt.Errorf("%w: %s contains no audio samples", ErrAudioDecode, path)}
+	}
+	return audioProbe{duration: time.Duration(seconds * float64(time.Second))}
+}
+
+// transcodeAudio converts inputPath to 16 kHz mono WAV at outPath
+func (m *MLXAdapter) transcodeAudio(ctx context.Context, inputPath, outPath string) error {
+	args := []string{"-y", "-hide_banner", "-loglevel", "error",
+		"-i", inputPath, "-ac", "1", "-ar", "16000", outPath}
+	if out, err := m.commandCombinedOutput(ctx, exec.CommandContext(ctx, "ffmpeg", args...)); err != nil {
+		if isAudioDecodeError(string(out)) {
+			return fmt.Errorf("failed to transcode: %w: %s: %w: %s", ErrAudioDecode, inputPath, err, strings.TrimSpace(string(out)))
+		}
+		return fmt.Errorf("failed to transcode %s to WAV: %w: %s", filepath.Base(inputPath), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// extractAudio writes duration seconds of audio starting at offset to outPath
+// as 16 kHz mono WAV, the format Whisper resamples to anyway. A duration of
+// zero or less extracts to the end of the file.
+func (m *MLXAdapter) extractAudio(ctx context.Context, inputPath string, offset, duration float64, outPath string) error {
+	args := []string{"-y", "-hide_banner", "-loglevel", "error",
+		"-ss", strconv.FormatFloat(offset, 'f', 3, 64),
+		"-i", inputPath,
+	}
+	if duration > 0 {
+		args = append(args, "-t", strconv.FormatFloat(duration, 'f', 3, 64))
+	}
+	args = append(args, "-ac", "1", "-ar", "16000", outPath)
+
+	if out, err := m.commandCombinedOutput(ctx, exec.CommandContext(ctx, "ffmpeg", args...)); err != nil {
+		if isAudioDecodeError(string(out)) {
+			return fmt.Errorf("failed to extract audio: %w: %s: %w: %s", ErrAudioDecode, inputPath, err, strings.TrimSpace(string(out)))
+		}
+		return fmt.Errorf("failed to extract audio from %.3fs: %w: %s", offset, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// mlxChunkOverlap is how many seconds adjacent chunks share in chunked mode,
+// so speech cut at one chunk's edge is heard whole by the other
+const mlxChunkOverlap = 5.0
+
+// chunkRanges splits duration seconds into chunks of length seconds where
+// each chunk starts overlap seconds before the previous one ends
+func chunkRanges(duration, length, overlap float64) [][2]float64 {
+	var chunks [][2]float64
+	for start := 0.0; ; start += length - overlap {
+		end := math.Min(start+length, duration)
+		chunks = append(chunks, [2]float64{start, end})
+		if end >= duration {
+			return chunks
+		}
+	}
+}
+
+// validateRanges checks that every range is non-empty, starts at or after 0
+// and, when the audio duration is known, starts before the end of the audio
+func validateRanges(ranges [][2]float64, duration float64) error {
+	for i, r := range ranges {
+		if r[0] < 0 || r[1] <= r[0] {
+			return fmt.Errorf("invalid range %d [%g, %g]: start must be >= 0 and before end", i, r[0], r[1])
+		}
+		if duration > 0 && r[0] >= duration {
+			return fmt.Errorf("range %d starts at %gs, beyond the end of the audio (%gs)", i, r[0], duration)
+		}
+	}
+	return nil
+}
+
+// writeRangesManifest extracts each range to its own file in dir and writes
+// the manifest the MLX script reads in ranges mode
+func (m *MLXAdapter) writeRangesManifest(ctx context.Context, inputPath string, ranges [][2]float64, dir string) (string, error) {
+	type rangeEntry struct {
+		Audio  string  `json:"audio"`
+		Offset float64 `json:"offset"`
+		Index  int     `json:"index"`
+	}
+
+	entries := make([]rangeEntry, len(ranges))
+	for i, r := range ranges {
+		audioPath := filepath.Join(dir, fmt.Sprintf("range_%03d.wav", i))
+		if err := m.extractAudio(ctx, inputPath, r[0], r[1]-r[0], audioPath); err != nil {
+			return "", fmt.Errorf("range %d: %w", i, err)
+		}
+		entries[i] = rangeEntry{Audio: audioPath, Offset: r[0], Index: i}
+	}
+
+	manifestPath := filepath.Join(dir, "ranges.json")
+	if err := writeJSONFile(manifestPath, entries); err != nil {
+		return "", fmt.Errorf("failed to write ranges manifest: %w", err)
+	}
+	return manifestPath, nil
+}
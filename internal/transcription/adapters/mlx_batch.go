@@ -0,0 +1,262 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// DirConfigFileName is the optional per-directory defaults file read by
+// TranscribeDir
+const DirConfigFileName = ".scriberr.json"
+
+// FileTranscription is the outcome for a single file of a multi-file run
+type FileTranscription struct {
+	Path   string                       `json:"path"`
+	Result *interfaces.TranscriptResult `json:"result,omitempty"`
+	Err    error                        `json:"-"`
+}
+
+// LoadDirConfig reads default parameters (e.g. language, model) from the
+// .scriberr.json file in dir. A missing file is not an error and yields an
+// empty map.
+func LoadDirConfig(dir string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filepath.Join(dir, DirConfigFileName))
+	if os.IsNotExist(err) {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", DirConfigFileName, err)
+	}
+
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s in %s: %w", DirConfigFileName, dir, err)
+	}
+	return config, nil
+}
+
+// mergeParams layers params over defaults, returning a new map
+func mergeParams(defaults, params map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults)+len(params))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	return merged
+}
+
+// TranscribeDir transcribes every supported audio file in dir, in name order.
+// Defaults from the directory's .scriberr.json apply underneath params. A
+// failure on one file is recorded in its FileTranscription and does not stop
+// the run; the returned error is only for problems with the directory itself
+// or cancellation.
+func (m *MLXAdapter) TranscribeDir(ctx context.Context, dir string, params map[string]interface{}, procCtx interfaces.ProcessingContext) ([]FileTranscription, error) {
+	dirConfig, err := LoadDirConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	params = mergeParams(dirConfig, params)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		format := strings.TrimPrefix(strings.ToLower(filepath.Ext(entry.Name())), ".")
+		if m.stringInSlice(format, m.capabilities.SupportedFormats) {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	// Outputs of different files share the output directory, so derive their
+	// names from the input file unless the caller chose a template
+	if procCtx.OutputNameTemplate == "" {
+		procCtx.OutputNameTemplate = interfaces.OutputNameBasename + "." + interfaces.OutputNameExt
+	}
+
+	results := make([]FileTranscription, 0, len(files))
+	for _, name := range files {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		path := filepath.Join(dir, name)
+		outcome := FileTranscription{Path: path}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			outcome.Err = err
+			results = append(results, outcome)
+			continue
+		}
+
+		input := interfaces.AudioInput{
+			FilePath: path,
+			Format:   strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), "."),
+			Size:     info.Size(),
+		}
+		outcome.Result, outcome.Err = m.Transcribe(ctx, input, params, procCtx)
+		results = append(results, outcome)
+	}
+
+	return results, nil
+}
+
+// batchItem is one input of a TranscribeBatch run
+type batchItem struct {
+	index int
+	input interfaces.AudioInput
+	jobID string
+
+	// output is where the subprocess writes the item's JSON; outputName is
+	// the name handed to an OutputSink
+	output     string
+	outputName string
+
+	result *interfaces.TranscriptResult
+	err    error
+}
+
+// TranscribeBatch transcribes several inputs in a single subprocess that
+// loads the model once, which is much faster than calling Transcribe in a
+// loop for many short files. Outcomes are returned in input order. A failure
+// on one file is recorded in its FileTranscription and does not stop the
+// batch; the returned error is only for problems with the batch as a whole.
+//
+// Each file gets the job ID procCtx.JobID-<index>. Batches do not support
+// ranges, chunk_length, start_from or overlap_voting, and do not use the
+// result cache, transient-failure retries or the OOM fallback.
+func (m *MLXAdapter) TranscribeBatch(ctx context.Context, inputs []interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext) ([]FileTranscription, error) {
+	if procCtx.JobID == "" {
+		procCtx.JobID = uuid.New().String()
+	}
+	params, err := m.applyQualityPreset(params)
+	if err == nil {
+		err = m.checkModelAllowed(params)
+	}
+	if err == nil {
+		params, err = m.ValidateAndApplyDefaults(params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(m.GetRangesParameter(params, "ranges")) > 0 || m.GetFloatParameter(params, "chunk_length") > 0 ||
+		m.GetFloatParameter(params, "start_from") > 0 || m.GetBoolParameter(params, "overlap_voting") {
+		return nil, fmt.Errorf("ranges, chunk_length, start_from and overlap_voting are not supported in batch mode")
+	}
+
+	// Outputs of different files share the output directory, so derive their
+	// names from the input file unless the caller chose a template
+	if procCtx.OutputNameTemplate == "" {
+		procCtx.OutputNameTemplate = interfaces.OutputNameBasename + "." + interfaces.OutputNameExt
+	}
+
+	ctx, cancel := m.withMaxDuration(ctx)
+	defer cancel()
+
+	results := make([]FileTranscription, len(inputs))
+	var batch []*batchItem
+	for i, input := range inputs {
+		item := &batchItem{index: i, input: input, jobID: fmt.Sprintf("%s-%d", procCtx.JobID, i)}
+		results[i].Path = input.FilePath
+
+		itemCtx := procCtx
+		itemCtx.JobID = item.jobID
+		prepared, inputDir, err := m.prepareInput(ctx, input, params, itemCtx)
+		defer m.CleanupTempDirectory(inputDir)
+		if err == nil {
+			err = m.ValidateAudioInput(prepared)
+		}
+		if err == nil {
+			prepared, err = m.probeInput(ctx, prepared, itemCtx)
+		}
+		if err != nil {
+			item.err = err
+		} else {
+			item.input = prepared
+			batch = append(batch, item)
+		}
+		results[i].Err = item.err
+	}
+	if len(batch) == 0 {
+		return results, nil
+	}
+
+	m.LogProcessingStart(batch[0].input, procCtx)
+	startTime := time.Now()
+	run := &mlxRun{jobID: procCtx.JobID, batch: batch}
+	_, err = m.transcribe(ctx, batch[0].input, params, procCtx, run)
+	err = timeoutError(ctx, err, time.Since(startTime), m.GetStringParameter(params, "model"))
+	m.LogProcessingEnd(procCtx, time.Since(startTime), err)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range batch {
+		results[item.index].Result, results[item.index].Err = item.result, item.err
+	}
+	return results, nil
+}
+
+// writeBatchManifest assigns each item its output files and writes the list
+// the Python bridge loops over, returning its path
+func writeBatchManifest(batch []*batchItem, tempDir string, procCtx interfaces.ProcessingContext) (string, error) {
+	type entry struct {
+		Audio  string `json:"audio"`
+		Output string `json:"output"`
+	}
+	entries := make([]entry, len(batch))
+	for i, item := range batch {
+		name, err := procCtx.OutputFileName(item.input.FilePath, "json", fmt.Sprintf("output-%d.json", i))
+		if err != nil {
+			return "", err
+		}
+		item.outputName = name
+		item.output = filepath.Join(tempDir, fmt.Sprintf("batch-%d.json", i))
+		entries[i] = entry{Audio: item.input.FilePath, Output: item.output}
+	}
+
+	path := filepath.Join(tempDir, "batch.json")
+	if err := writeJSONFile(path, entries); err != nil {
+		return "", fmt.Errorf("failed to write batch manifest: %w", err)
+	}
+	return path, nil
+}
+
+// batchOutputError returns the error the Python bridge recorded for one
+// batch file, if any, classifying decode failures of audioPath as
+// ErrAudioDecode
+func batchOutputError(path, audioPath string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("no MLX output for this file: %w", err)
+	}
+	var output struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &output) == nil && output.Error != "" {
+		if isAudioDecodeError(output.Error) {
+			return fmt.Errorf("MLX transcription failed: %w: %s: %s", ErrAudioDecode, audioPath, output.Error)
+		}
+		return fmt.Errorf("MLX transcription failed: %s", output.Error)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package adapters
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// cacheNeutralParams are parameters that do not affect the transcript and are
+// therefore left out of the parameter fingerprint
+var cacheNeutralParams = map[string]bool{
+	"skip_if_fresh": true,
+	"split_streams": true,
+	"debug_dump":    true,
+}
+
+// paramsFingerprint returns a stable hash of the effective parameter set, the
+// request's environment variables and the configured translator and
+// analyzer, which can all change the transcript. Schema defaults are filled
+// in so that omitting a parameter and passing its default produce the same
+// fingerprint; unknown keys are ignored.
+func (m *MLXAdapter) paramsFingerprint(params map[string]interface{}, env map[string]string) string {
+	effective := make(map[string]interface{}, len(m.schema))
+	for _, p := range m.schema {
+		if cacheNeutralParams[p.Name] {
+			continue
+		}
+		effective[p.Name] = m.GetParameterWithDefault(params, p.Name)
+	}
+
+	// encoding/json sorts map keys, which keeps the encoding stable
+	data, _ := json.Marshal(struct {
+		Params     map[string]interface{} `json:"params"`
+		Env        map[string]string      `json:"env,omitempty"`
+		Translator string                 `json:"translator,omitempty"`
+		Analyzer   string                 `json:"analyzer,omitempty"`
+	}{effective, env, componentFingerprint(m.translator), componentFingerprint(m.analyzer)})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// componentFingerprint describes a configured translator or analyzer by its
+// type and exported fields, or "" if there is none
+func componentFingerprint(component interface{}) string {
+	if component == nil {
+		return ""
+	}
+	data, err := json.Marshal(component)
+	if err != nil {
+		return fmt.Sprintf("%T", component)
+	}
+	return fmt.Sprintf("%T %s", component, data)
+}
+
+// freshResultPath is where skip_if_fresh keeps the saved result
+func (m *MLXAdapter) freshResultPath(input interfaces.AudioInput, procCtx interfaces.ProcessingContext) (string, error) {
+	name, err := procCtx.OutputFileName(input.FilePath, "json", "output.json")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(procCtx.OutputDirectory, name), nil
+}
+
+// loadFreshResult returns the saved result if it is newer than the audio file
+// and was produced with the same parameters. Results handed to an output
+// sink cannot be read back, so there is none with a sink.
+func (m *MLXAdapter) loadFreshResult(input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, bool) {
+	if procCtx.OutputSink != nil {
+		return nil, false
+	}
+	path, err := m.freshResultPath(input, procCtx)
+	if err != nil {
+		return nil, false
+	}
+
+	resultInfo, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	audioInfo, err := os.Stat(input.FilePath)
+	if err != nil || !resultInfo.ModTime().After(audioInfo.ModTime()) {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var result interfaces.TranscriptResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false
+	}
+	if result.Metadata["params_hash"] != m.paramsFingerprint(params, procCtx.Env) {
+		return nil, false
+	}
+
+	return &result, true
+}
+
+// saveFreshResult tags the result with the parameter fingerprint and stores
+// it next to the other outputs for later skip_if_fresh runs. With an output
+// sink nothing is written locally; the caller puts the tagged result in the
+// sink.
+func (m *MLXAdapter) saveFreshResult(result *interfaces.TranscriptResult, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext) error {
+	path, err := m.freshResultPath(input, procCtx)
+	if err != nil {
+		return err
+	}
+
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]string)
+	}
+	result.Metadata["params_hash"] = m.paramsFingerprint(params, procCtx.Env)
+
+	if procCtx.OutputSink != nil {
+		return nil
+	}
+	return writeJSONFile(path, result)
+}
+
+// DefaultResultCacheDir is the result cache location inside the MLX
+// environment, for use with SetResultCache
+func (m *MLXAdapter) DefaultResultCacheDir() string {
+	return filepath.Join(m.envPath, "MLX", "result-cache")
+}
+
+// SetResultCache enables a cache of results keyed by the SHA-256 of the audio
+// bytes and the effective parameters, so resubmitting the same file returns
+// the stored result without running the model. Pass an empty string to
+// disable it.
+func (m *MLXAdapter) SetResultCache(dir string) {
+	m.resultCacheDir = dir
+}
+
+// ClearResultCache removes every cached result
+func (m *MLXAdapter) ClearResultCache() error {
+	if m.resultCacheDir == "" {
+		return nil
+	}
+	if err := os.RemoveAll(m.resultCacheDir); err != nil {
+		return fmt.Errorf("failed to clear MLX result cache: %w", err)
+	}
+	return nil
+}
+
+// resultCacheKey returns the cache key for input, params and env, or "" if
+// the cache is disabled or the audio cannot be read
+func (m *MLXAdapter) resultCacheKey(input interfaces.AudioInput, params map[string]interface{}, env map[string]string) string {
+	if m.resultCacheDir == "" {
+		return ""
+	}
+	f, err := os.Open(input.FilePath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return ""
+	}
+	hash.Write([]byte(m.paramsFingerprint(params, env)))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// loadCachedResult returns the cached result for key, if any
+func (m *MLXAdapter) loadCachedResult(key string) (*interfaces.TranscriptResult, bool) {
+	if key == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(m.resultCacheDir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var result interfaces.TranscriptResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
+
+// saveCachedResult stores result under key
+func (m *MLXAdapter) saveCachedResult(key string, result *interfaces.TranscriptResult) error {
+	if key == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.resultCacheDir, 0755); err != nil {
+		return err
+	}
+	return writeJSONFile(filepath.Join(m.resultCacheDir, key+".json"), result)
+}
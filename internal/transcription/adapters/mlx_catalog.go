@@ -0,0 +1,181 @@
+package adapters
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"scriberr/pkg/logger"
+)
+
+// ModelInfo describes an MLX Whisper model that can be passed as the "model"
+// parameter
+type ModelInfo struct {
+	ID string `json:"id"`
+
+	// SizeBytes is the size of the unquantized repository, or of the smallest
+	// variant if there is none. Zero means unknown.
+	SizeBytes int64 `json:"size_bytes"`
+
+	// Quantizations lists the values of the "quantization" parameter that
+	// have weights on the hub ("none", "4bit", "8bit")
+	Quantizations []string `json:"quantizations"`
+}
+
+// mlxModelListURL lists the Whisper conversions published by mlx-community
+var mlxModelListURL = "https://huggingface.co/api/models?author=mlx-community&search=whisper&expand[]=usedStorage&limit=1000"
+
+// mlxCatalogTTL is how long GetSupportedModels reuses a model list from the
+// hub, and mlxCatalogRetryTTL how long it waits to try the hub again after
+// failing to reach it
+const (
+	mlxCatalogTTL      = time.Hour
+	mlxCatalogRetryTTL = 5 * time.Minute
+)
+
+// mlxModelManifest is the curated list used offline or when the hub cannot
+// be reached. Sizes are approximate.
+//
+//go:embed mlx_models.json
+var mlxModelManifest []byte
+
+// ListAvailableModels asks the Hugging Face hub for the mlx-community Whisper
+// models, grouping "-4bit" and "-8bit" repositories under their base model.
+// Offline, or if the hub cannot be reached, it returns the bundled manifest.
+func (m *MLXAdapter) ListAvailableModels(ctx context.Context) ([]ModelInfo, error) {
+	if !m.offline() {
+		models, err := fetchHubModels(ctx)
+		if err == nil {
+			return models, nil
+		}
+		logger.Warn("Could not list MLX models on the hub, using the bundled list", "error", err)
+	}
+	return bundledModels()
+}
+
+// cachedModels returns the cached model list without waiting for the hub.
+// Until the hub has answered that is the bundled manifest. Once the list has
+// expired it is refreshed in the background, and a failed refresh keeps the
+// current list for mlxCatalogRetryTTL.
+func (m *MLXAdapter) cachedModels() []ModelInfo {
+	m.catalogMu.Lock()
+	defer m.catalogMu.Unlock()
+	if m.catalog == nil {
+		models, err := bundledModels()
+		if err != nil {
+			logger.Error("Bundled MLX model list is invalid", "error", err)
+		}
+		m.catalog = models
+	}
+	if !m.offline() && !m.catalogRefreshing && time.Now().After(m.catalogExpires) {
+		m.catalogRefreshing = true
+		go m.refreshCatalog()
+	}
+	return m.catalog
+}
+
+// refreshCatalog replaces the cached model list with the hub's
+func (m *MLXAdapter) refreshCatalog() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	models, err := fetchHubModels(ctx)
+
+	m.catalogMu.Lock()
+	defer m.catalogMu.Unlock()
+	m.catalogRefreshing = false
+	if err != nil {
+		logger.Warn("Could not list MLX models on the hub, keeping the current list", "error", err)
+		m.catalogExpires = time.Now().Add(mlxCatalogRetryTTL)
+		return
+	}
+	m.catalog, m.catalogExpires = models, time.Now().Add(mlxCatalogTTL)
+}
+
+// bundledModels parses the embedded manifest
+func bundledModels() ([]ModelInfo, error) {
+	var models []ModelInfo
+	if err := json.Unmarshal(mlxModelManifest, &models); err != nil {
+		return nil, fmt.Errorf("failed to parse bundled MLX model list: %w", err)
+	}
+	return models, nil
+}
+
+// fetchHubModels queries mlxModelListURL
+func fetchHubModels(ctx context.Context) ([]ModelInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", mlxModelListURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("hub API error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var repos []struct {
+		ID          string `json:"id"`
+		UsedStorage int64  `json:"usedStorage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
+		return nil, fmt.Errorf("failed to parse hub model list: %w", err)
+	}
+
+	byID := make(map[string]*ModelInfo)
+	for _, repo := range repos {
+		if !strings.Contains(strings.ToLower(repo.ID), "whisper") {
+			continue
+		}
+		base, quantization := repo.ID, "none"
+		for _, tag := range []string{"4bit", "8bit"} {
+			if trimmed, ok := strings.CutSuffix(repo.ID, "-"+tag); ok {
+				base, quantization = trimmed, tag
+			}
+		}
+
+		info, ok := byID[base]
+		if !ok {
+			info = &ModelInfo{ID: base}
+			byID[base] = info
+		}
+		info.Quantizations = append(info.Quantizations, quantization)
+		if quantization == "none" || info.SizeBytes == 0 || (repo.UsedStorage > 0 && repo.UsedStorage < info.SizeBytes && !hasQuantization(info, "none")) {
+			info.SizeBytes = repo.UsedStorage
+		}
+	}
+
+	models := make([]ModelInfo, 0, len(byID))
+	for _, info := range byID {
+		sort.Slice(info.Quantizations, func(i, j int) bool {
+			// "none" first, then by bit width
+			qi, qj := info.Quantizations[i], info.Quantizations[j]
+			if (qi == "none") != (qj == "none") {
+				return qi == "none"
+			}
+			return qi < qj
+		})
+		models = append(models, *info)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+// hasQuantization reports whether info lists quantization
+func hasQuantization(info *ModelInfo, quantization string) bool {
+	for _, q := range info.Quantizations {
+		if q == quantization {
+			return true
+		}
+	}
+	return false
+}
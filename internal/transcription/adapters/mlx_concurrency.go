@@ -0,0 +1,50 @@
+package adapters
+
+import "context"
+
+// defaultMLXConcurrency allows as many concurrent transcriptions as fit in
+// half of physical memory at the adapter's MemoryRequirement (in MB), leaving
+// the rest for the system. It is at least 1.
+func defaultMLXConcurrency(memoryRequirementMB int) int {
+	if memoryRequirementMB <= 0 {
+		return 1
+	}
+	n := int(totalMemoryBytes() / 2 / (uint64(memoryRequirementMB) << 20))
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// SetMaxConcurrency limits how many transcriptions may run their subprocess
+// at the same time. Values <= 0 remove the limit. Runs already in progress
+// keep the slot they acquired under the previous limit.
+func (m *MLXAdapter) SetMaxConcurrency(n int) {
+	m.transcribeMu.Lock()
+	defer m.transcribeMu.Unlock()
+
+	if n <= 0 {
+		m.transcribeSem = nil
+		return
+	}
+	m.transcribeSem = make(chan struct{}, n)
+}
+
+// acquireTranscribeSlot blocks until a transcription slot is free or ctx is
+// done. The returned function releases the slot.
+func (m *MLXAdapter) acquireTranscribeSlot(ctx context.Context) (func(), error) {
+	m.transcribeMu.Lock()
+	sem := m.transcribeSem
+	m.transcribeMu.Unlock()
+
+	if sem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
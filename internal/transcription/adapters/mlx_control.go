@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// skipSegmentFile is the control file the MLX script watches for in its
+// control directory. Its presence asks the script to abandon the segment it is
+// currently decoding.
+const skipSegmentFile = "skip_segment"
+
+// SkippedSegmentText replaces the text of a segment abandoned via
+// SkipCurrentSegment
+const SkippedSegmentText = "[skipped]"
+
+// trackJob records the control directory of a running job
+func (m *MLXAdapter) trackJob(jobID, controlDir string) {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	m.activeJobs[jobID] = controlDir
+}
+
+// untrackJob forgets a job once its subprocess has exited
+func (m *MLXAdapter) untrackJob(jobID string) {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	delete(m.activeJobs, jobID)
+}
+
+// SkipCurrentSegment asks a running job to abandon the segment it is decoding
+// (e.g. one stuck in a repetition loop) and continue with the next. The
+// abandoned segment is kept in the result with SkippedSegmentText as its text.
+// Segments are only skipped while the decoder is running; a request that
+// arrives between segments is dropped.
+func (m *MLXAdapter) SkipCurrentSegment(jobID string) error {
+	m.jobsMu.Lock()
+	controlDir, ok := m.activeJobs[jobID]
+	m.jobsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no running MLX job with ID %s", jobID)
+	}
+
+	if err := os.WriteFile(filepath.Join(controlDir, skipSegmentFile), nil, 0644); err != nil {
+		return fmt.Errorf("failed to signal segment skip: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+)
+
+const redacted = "[REDACTED]"
+
+// mlxDebugDump is the content of the debug.json written by debug_dump
+type mlxDebugDump struct {
+	JobID          string                 `json:"job_id"`
+	AdapterVersion string                 `json:"adapter_version"`
+	Model          string                 `json:"model"`
+	AudioFile      string                 `json:"audio_file"`
+	Params         map[string]interface{} `json:"params"`
+	Command        []string               `json:"command,omitempty"`
+	Environment    map[string]string      `json:"environment"`
+	StartedAt      time.Time              `json:"started_at"`
+	Duration       string                 `json:"duration"`
+	Error          string                 `json:"error,omitempty"`
+}
+
+// newDebugDump starts a dump with the environment diagnostics filled in
+func (m *MLXAdapter) newDebugDump(input interfaces.AudioInput, procCtx interfaces.ProcessingContext, startTime time.Time) *mlxDebugDump {
+	env := map[string]string{
+		"os":                runtime.GOOS,
+		"arch":              runtime.GOARCH,
+		"go_version":        runtime.Version(),
+		"env_path":          m.envPath,
+		"environment_ready": "false",
+	}
+	if m.Ready() {
+		env["environment_ready"] = "true"
+	}
+	if uvPath, err := exec.LookPath("uv"); err == nil {
+		env["uv_path"] = uvPath
+	} else {
+		env["uv_path"] = "not found"
+	}
+	if m.readOnlyCacheDir != "" {
+		env["read_only_model_cache"] = m.readOnlyCacheDir
+	}
+	if m.offlineCacheDir != "" {
+		env["offline_cache"] = m.offlineCacheDir
+	}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, "HF_") || strings.HasPrefix(name, "HUGGING") {
+			if isSecretName(name) {
+				value = redacted
+			}
+			env[name] = value
+		}
+	}
+
+	return &mlxDebugDump{
+		JobID:          procCtx.JobID,
+		AdapterVersion: m.GetCapabilities().Version,
+		AudioFile:      input.FilePath,
+		Environment:    env,
+		StartedAt:      startTime,
+	}
+}
+
+// finish records the run time and outcome
+func (d *mlxDebugDump) finish(err error) {
+	d.Duration = time.Since(d.StartedAt).String()
+	if err != nil {
+		d.Error = err.Error()
+	}
+}
+
+// resolvedParams returns every schema parameter with defaults filled in and
+// secret-looking values redacted
+func (m *MLXAdapter) resolvedParams(params map[string]interface{}) map[string]interface{} {
+	resolved := make(map[string]interface{}, len(m.schema))
+	for _, p := range m.schema {
+		resolved[p.Name] = m.GetParameterWithDefault(params, p.Name)
+	}
+	for name, value := range params {
+		if _, ok := resolved[name]; !ok {
+			resolved[name] = value
+		}
+	}
+	for name := range resolved {
+		if isSecretName(name) {
+			resolved[name] = redacted
+		}
+	}
+	return resolved
+}
+
+// isSecretName reports whether a parameter or variable name looks like it
+// holds a credential
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"token", "secret", "password", "key"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeDebugDump writes debug.json to the output sink or directory. Failures
+// are logged; the dump never fails the job.
+func (m *MLXAdapter) writeDebugDump(procCtx interfaces.ProcessingContext, inputPath string, dump *mlxDebugDump) {
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		logger.Warn("Failed to encode debug dump", "job_id", procCtx.JobID, "error", err)
+		return
+	}
+
+	name, err := procCtx.OutputFileName(inputPath, "debug.json", "debug.json")
+	if err != nil {
+		name = "debug.json"
+	}
+	if procCtx.OutputSink != nil {
+		err = procCtx.OutputSink.Put(name, bytes.NewReader(data))
+	} else {
+		err = os.WriteFile(filepath.Join(procCtx.OutputDirectory, name), data, 0644)
+	}
+	if err != nil {
+		logger.Warn("Failed to write debug dump", "job_id", procCtx.JobID, "error", err)
+	}
+}
+
+// dryRunResult is the stub returned for ProcessingContext.DryRun. Its
+// metadata holds the argv (as a JSON array and as a shell command line), the
+// bridge script path and the resolved model. Files the command refers to in
+// the job temp directory are removed when Transcribe returns.
+func dryRunResult(argv []string, scriptPath, model, quantization, jobID string) *interfaces.TranscriptResult {
+	encoded, _ := json.Marshal(argv)
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	return &interfaces.TranscriptResult{
+		ModelUsed: model,
+		JobID:     jobID,
+		Segments:  []interfaces.TranscriptSegment{},
+		Metadata: map[string]string{
+			"dry_run":      "true",
+			"argv":         string(encoded),
+			"command":      strings.Join(quoted, " "),
+			"script_path":  scriptPath,
+			"model":        model,
+			"quantization": quantization,
+		},
+	}
+}
+
+// shellQuote quotes s for a POSIX shell if it contains anything but safe
+// characters
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,+@%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,115 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+
+	"github.com/google/uuid"
+)
+
+// mlxDetectLanguageScript runs Whisper's language detection on the first 30
+// seconds of audio without decoding any text. It prints one JSON object with
+// the most likely language and its probability.
+const mlxDetectLanguageScript = `
+import json
+import os
+import sys
+
+import mlx.core as mx
+from mlx_whisper.audio import N_FRAMES, N_SAMPLES, load_audio, log_mel_spectrogram, pad_or_trim
+from mlx_whisper.load_models import load_model
+
+audio_path, model_path, local_only = sys.argv[1], sys.argv[2], sys.argv[3] == "1"
+if local_only and not os.path.exists(model_path):
+    from huggingface_hub import snapshot_download
+    model_path = snapshot_download(repo_id=model_path, local_files_only=True)
+
+model = load_model(model_path, dtype=mx.float16)
+if not model.is_multilingual:
+    print(json.dumps({"language": "en", "probability": 1.0}))
+    sys.exit(0)
+
+audio = load_audio(audio_path)[:N_SAMPLES]
+mel = log_mel_spectrogram(audio, n_mels=model.dims.n_mels, padding=N_SAMPLES)
+mel = pad_or_trim(mel, N_FRAMES, axis=-2).astype(mx.float16)
+_, probs = model.detect_language(mel)
+language = max(probs, key=probs.get)
+print(json.dumps({"language": language, "probability": float(probs[language])}))
+`
+
+// DetectLanguage identifies the spoken language from the first 30 seconds of
+// the input using the default model, returning the language code and the
+// model's probability for it. No text is decoded, so this is much cheaper
+// than a full transcription. procCtx.Env applies as for Transcribe; a missing
+// job ID or temp directory is filled in.
+func (m *MLXAdapter) DetectLanguage(ctx context.Context, input interfaces.AudioInput, procCtx interfaces.ProcessingContext) (string, float64, error) {
+	if err := m.ValidateAudioInput(input); err != nil {
+		return "", 0, err
+	}
+
+	if procCtx.JobID == "" {
+		procCtx.JobID = "detect-" + uuid.New().String()
+	}
+	if procCtx.TempDirectory == "" {
+		procCtx.TempDirectory = os.TempDir()
+	}
+	// Streamed input is spooled to a file, as for a transcription
+	input, inputDir, err := m.prepareInput(ctx, input, nil, procCtx)
+	defer m.CleanupTempDirectory(inputDir)
+	if err != nil {
+		return "", 0, err
+	}
+	tempDir, err := m.CreateTempDirectory(procCtx)
+	if err != nil {
+		return "", 0, err
+	}
+	defer m.CleanupTempDirectory(tempDir)
+
+	model := m.GetStringParameter(nil, "model")
+	localOnly := "0"
+	if m.offline() {
+		localOnly = "1"
+	}
+
+	mlxPath := filepath.Join(m.envPath, "MLX")
+	cmd := exec.CommandContext(ctx, "uv", "run", "--project", mlxPath, "python", "-c", mlxDetectLanguageScript,
+		input.FilePath, model, localOnly)
+	configureCmdSysProcAttr(cmd)
+	cmd.Cancel = func() error { return killProcessTree(cmd.Process) }
+	cmd.WaitDelay = 5 * time.Second
+	cmd.Env = withRequestEnv(m.subprocessEnv(tempDir), procCtx.Env)
+
+	stderr := &tailBuffer{max: 4096}
+	cmd.Stderr = stderr
+
+	// Detection loads a model too, so it counts against the concurrency limit
+	release, err := m.acquireTranscribeSlot(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("MLX language detection cancelled while waiting for a slot: %w", err)
+	}
+	out, err := m.commandOutput(ctx, cmd)
+	release()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", 0, fmt.Errorf("MLX language detection cancelled: %w", ctx.Err())
+		}
+		return "", 0, fmt.Errorf("MLX language detection failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var detected struct {
+		Language    string  `json:"language"`
+		Probability float64 `json:"probability"`
+	}
+	if err := json.Unmarshal([]byte(lastLine(string(out))), &detected); err != nil {
+		return "", 0, fmt.Errorf("failed to parse language detection output: %w", err)
+	}
+	return detected.Language, detected.Probability, nil
+}
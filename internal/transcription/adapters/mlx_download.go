@@ -0,0 +1,274 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"scriberr/pkg/logger"
+)
+
+// defaultMLXDownloadConcurrency is the number of model downloads allowed to run
+// at once. Downloads are network bound, so this is independent of (and usually
+// higher than) the number of concurrent transcriptions.
+const defaultMLXDownloadConcurrency = 4
+
+// mlxQuantizationDownloadPy is shared by the download and transcription
+// scripts. It fetches only the weight files of one quantization variant (plus
+// JSON config) and returns the directory holding them.
+//
+//go:embed mlx_quantization.py
+var mlxQuantizationDownloadPy string
+
+// mlxDownloadScript fetches a model repository into the Hugging Face cache
+// without loading it. An optional second argument limits the download to one
+// quantization variant.
+var mlxDownloadScript = mlxQuantizationDownloadPy + `
+import sys
+from huggingface_hub import snapshot_download
+
+if len(sys.argv) > 2 and sys.argv[2]:
+    path = download_quantization(sys.argv[1], sys.argv[2])
+else:
+    path = snapshot_download(repo_id=sys.argv[1])
+print(path)
+`
+
+// mlxModelSizeScript prints the total size in bytes of the files a download
+// would fetch
+var mlxModelSizeScript = mlxQuantizationDownloadPy + `
+import sys
+from huggingface_hub import HfApi
+
+info = HfApi().model_info(sys.argv[1], files_metadata=True)
+sizes = {s.rfilename: s.size or 0 for s in info.siblings}
+names = list(sizes)
+if len(sys.argv) > 2 and sys.argv[2]:
+    names, _ = select_quantization(names, sys.argv[2])
+print(sum(sizes[n] for n in names))
+`
+
+// mlxModelCachedScript exits successfully if the model is already in the
+// local Hugging Face cache
+const mlxModelCachedScript = `
+import sys
+from huggingface_hub import snapshot_download
+
+snapshot_download(repo_id=sys.argv[1], local_files_only=True)
+`
+
+// ErrDownloadRejected is returned when the DownloadApprover declines a download
+var ErrDownloadRejected = errors.New("model download rejected")
+
+// DownloadApprover decides whether a model download may start. sizeBytes is
+// the estimated download size, or -1 if it could not be determined.
+type DownloadApprover func(modelID string, sizeBytes int64) bool
+
+// SetDownloadApprover installs a hook that EnsureModel and DownloadModel call
+// before fetching any weights, e.g. to prompt the user in interactive use.
+// Pass nil to allow all downloads.
+func (m *MLXAdapter) SetDownloadApprover(approve DownloadApprover) {
+	m.downloadMu.Lock()
+	defer m.downloadMu.Unlock()
+	m.approver = approve
+}
+
+// EnsureModel makes sure modelID is in the local cache, downloading it (subject
+// to the DownloadApprover) only if it is missing
+func (m *MLXAdapter) EnsureModel(ctx context.Context, modelID string) error {
+	if m.modelCached(ctx, modelID) {
+		return nil
+	}
+	return m.DownloadModel(ctx, modelID)
+}
+
+// modelCached reports whether modelID can be loaded without the network
+func (m *MLXAdapter) modelCached(ctx context.Context, modelID string) bool {
+	mlxPath := filepath.Join(m.envPath, "MLX")
+	cmd := exec.CommandContext(ctx, "uv", "run", "--project", mlxPath, "python", "-c", mlxModelCachedScript, modelID)
+	cmd.Env = m.subprocessEnv(os.TempDir())
+	return m.runCommand(ctx, cmd) == nil
+}
+
+// offlineModelRepo returns the repository transcribe_mlx.py loads for model
+// at quantization when it runs with --local-files-only. mlx-community
+// publishes each quantization as its own -4bit/-8bit repository, and offline
+// the script cannot ask the hub whether one exists, so it always uses it.
+func offlineModelRepo(model, quantization string) string {
+	if quantization != "4bit" && quantization != "8bit" {
+		return model
+	}
+	if strings.HasSuffix(model, "-4bit") || strings.HasSuffix(model, "-8bit") {
+		return model
+	}
+	if _, err := os.Stat(model); err == nil {
+		// A local model directory
+		return model
+	}
+	return model + "-" + quantization
+}
+
+// estimateDownloadSize asks the hub for the size of a download. It returns -1
+// if the size is unknown.
+func (m *MLXAdapter) estimateDownloadSize(ctx context.Context, modelID, quantization string) int64 {
+	mlxPath := filepath.Join(m.envPath, "MLX")
+	cmd := exec.CommandContext(ctx, "uv", "run", "--project", mlxPath, "python", "-c", mlxModelSizeScript, modelID, quantization)
+	out, err := m.commandOutput(ctx, cmd)
+	if err != nil {
+		logger.Warn("Could not determine MLX model size", "model", modelID, "error", err)
+		return -1
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(lastLine(string(out))), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+// lastLine returns the last non-empty line of s
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
+
+// SetMaxDownloadConcurrency limits how many DownloadModel calls may fetch
+// weights at the same time. Values <= 0 remove the limit. Downloads already in
+// progress keep the slot they acquired under the previous limit.
+func (m *MLXAdapter) SetMaxDownloadConcurrency(n int) {
+	m.downloadMu.Lock()
+	defer m.downloadMu.Unlock()
+
+	if n <= 0 {
+		m.downloadSem = nil
+		return
+	}
+	m.downloadSem = make(chan struct{}, n)
+}
+
+// acquireDownloadSlot blocks until a download slot is free or ctx is done.
+// The returned function releases the slot.
+func (m *MLXAdapter) acquireDownloadSlot(ctx context.Context) (func(), error) {
+	m.downloadMu.Lock()
+	sem := m.downloadSem
+	m.downloadMu.Unlock()
+
+	if sem == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// DownloadModel fetches the weights for modelID into the Hugging Face cache
+// without transcribing anything. It respects the download concurrency limit.
+func (m *MLXAdapter) DownloadModel(ctx context.Context, modelID string) error {
+	return m.DownloadModelQuantization(ctx, modelID, "")
+}
+
+// DownloadModelQuantization is like DownloadModel but, when quantization is
+// set ("4bit", "8bit" or "none"), fetches only the weight files for that
+// variant. Repositories holding a single variant are downloaded whole.
+func (m *MLXAdapter) DownloadModelQuantization(ctx context.Context, modelID, quantization string) error {
+	if modelID == "" {
+		return fmt.Errorf("model ID is required")
+	}
+	if m.readOnlyCacheDir != "" {
+		return fmt.Errorf("cannot download %s: model cache %s is read-only", modelID, m.readOnlyCacheDir)
+	}
+	if m.offlineCacheDir != "" {
+		return fmt.Errorf("%w: %s (offline)", ErrModelNotCached, modelID)
+	}
+
+	m.downloadMu.Lock()
+	approve := m.approver
+	m.downloadMu.Unlock()
+	if approve != nil {
+		size := m.estimateDownloadSize(ctx, modelID, quantization)
+		if !approve(modelID, size) {
+			return fmt.Errorf("%w: %s", ErrDownloadRejected, modelID)
+		}
+	}
+
+	release, err := m.acquireDownloadSlot(ctx)
+	if err != nil {
+		return fmt.Errorf("waiting for download slot: %w", err)
+	}
+	defer release()
+
+	logger.Info("Downloading MLX model", "model", modelID, "quantization", quantization)
+
+	// The environment is inherited, so HF_HOME and HF_HUB_CACHE decide where
+	// the weights go. huggingface_hub draws tqdm bars on stderr, which are
+	// forwarded to the log.
+	mlxPath := filepath.Join(m.envPath, "MLX")
+	cmd := exec.CommandContext(ctx, "uv", "run", "--project", mlxPath, "python", "-c", mlxDownloadScript, modelID, quantization)
+	output := &tailBuffer{max: 4096}
+	cmd.Stdout = output
+	cmd.Stderr = io.MultiWriter(output, &downloadProgressLogger{modelID: modelID})
+	if err := m.runCommand(ctx, cmd); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("download of %s cancelled: %w", modelID, ctx.Err())
+		}
+		return fmt.Errorf("failed to download model %s: %w: %s", modelID, err, strings.TrimSpace(output.String()))
+	}
+
+	logger.Info("MLX model downloaded", "model", modelID)
+	return nil
+}
+
+// SetWarmupModels sets models that PrepareEnvironment downloads into the
+// Hugging Face cache if they are not already there, so that deployments can
+// stage weights up front instead of on the first transcription.
+func (m *MLXAdapter) SetWarmupModels(models ...string) {
+	m.warmupModels = models
+}
+
+// warmUpModels downloads the configured warm-up models that are not cached
+func (m *MLXAdapter) warmUpModels(ctx context.Context) error {
+	for _, model := range m.warmupModels {
+		if err := m.EnsureModel(ctx, model); err != nil {
+			return fmt.Errorf("model warm-up failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// downloadProgressLogger logs the progress bar updates huggingface_hub writes
+// to stderr, at most once every few seconds
+type downloadProgressLogger struct {
+	modelID string
+	partial []byte
+	last    time.Time
+}
+
+func (l *downloadProgressLogger) Write(p []byte) (int, error) {
+	l.partial = append(l.partial, p...)
+	// tqdm redraws with carriage returns, so both end a progress line
+	for {
+		i := bytes.IndexAny(l.partial, "\r\n")
+		if i < 0 {
+			break
+		}
+		line := strings.TrimSpace(string(l.partial[:i]))
+		l.partial = l.partial[i+1:]
+		if line != "" && time.Since(l.last) >= 5*time.Second {
+			l.last = time.Now()
+			logger.Info("MLX model download progress", "model", l.modelID, "progress", line)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,59 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Health check failures, one per category, for use with errors.Is
+var (
+	ErrPlatformUnsupported = errors.New("platform not supported")
+	ErrUVUnavailable       = errors.New("uv unavailable")
+	ErrEnvironmentMissing  = errors.New("MLX environment not set up")
+	ErrMLXImportFailed     = errors.New("mlx_whisper cannot be imported")
+)
+
+// mlxHealthTTL is how long a HealthCheck result is reused
+const mlxHealthTTL = 30 * time.Second
+
+// HealthCheck verifies that the MLX environment is usable without running a
+// transcription: the platform is supported, uv is installed, the MLX project
+// exists and mlx_whisper imports. Results are cached for a short while, so it
+// is cheap to call before routing each job.
+func (m *MLXAdapter) HealthCheck(ctx context.Context) error {
+	m.healthMu.Lock()
+	defer m.healthMu.Unlock()
+
+	if !m.healthCheckedAt.IsZero() && time.Since(m.healthCheckedAt) < mlxHealthTTL {
+		return m.healthErr
+	}
+	m.healthErr = m.checkHealth(ctx)
+	m.healthCheckedAt = time.Now()
+	return m.healthErr
+}
+
+// checkHealth runs the checks behind HealthCheck, cheapest first
+func (m *MLXAdapter) checkHealth(ctx context.Context) error {
+	if available, reason := m.Available(); !available {
+		return fmt.Errorf("%w: %s", ErrPlatformUnsupported, reason)
+	}
+	if err := checkUV(ctx, m.commandRunner()); err != nil {
+		return fmt.Errorf("%w: %w", ErrUVUnavailable, err)
+	}
+	if m.envPathErr != nil {
+		return fmt.Errorf("%w: %w", ErrEnvironmentMissing, m.envPathErr)
+	}
+
+	mlxPath := filepath.Join(m.envPath, "MLX")
+	if _, err := os.Stat(filepath.Join(mlxPath, "pyproject.toml")); err != nil {
+		return fmt.Errorf("%w: no project in %s; run PrepareEnvironment", ErrEnvironmentMissing, mlxPath)
+	}
+	if !checkEnvironmentReady(ctx, m.commandRunner(), mlxPath, "import mlx_whisper") {
+		return fmt.Errorf("%w in %s", ErrMLXImportFailed, mlxPath)
+	}
+	return nil
+}
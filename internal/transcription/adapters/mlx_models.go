@@ -0,0 +1,214 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrModelNotFound is returned when the model repository does not exist on
+// the Hugging Face hub
+var ErrModelNotFound = errors.New("MLX model not found")
+
+// ErrModelNotAllowed is returned when the model parameter is not in the list
+// set with SetAllowedModels
+var ErrModelNotAllowed = errors.New("MLX model not allowed")
+
+// ErrModelNotCached is returned in offline mode when the model is not in the
+// local Hugging Face cache
+var ErrModelNotCached = errors.New("MLX model not in offline cache")
+
+// modelNotFoundMarkers are what huggingface_hub prints for a missing or
+// misspelled repository
+var modelNotFoundMarkers = []string{
+	"RepositoryNotFoundError",
+	"Repository Not Found",
+	"404 Client Error",
+}
+
+// isModelNotFound reports whether subprocess stderr shows a missing repository
+func isModelNotFound(stderrTail string) bool {
+	for _, marker := range modelNotFoundMarkers {
+		if strings.Contains(stderrTail, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// mlxModelSizes are approximate fp16 weight sizes in MB by checkpoint size,
+// checked in order against the model name
+var mlxModelSizes = []struct {
+	name string
+	mb   int
+}{
+	{"tiny", 75},
+	{"base", 145},
+	{"small", 485},
+	{"medium", 1530},
+	{"turbo", 1620},
+	{"large", 3100},
+}
+
+// EstimateMemory returns an upper bound in MB for a run with params. The
+// fp16 weight size is looked up from the checkpoint size in the model name
+// (unknown models count as large) and scaled for quantization: 8bit weights
+// take about half of fp16 and 4bit about a quarter. Activations, the KV cache
+// and the audio are not quantized and scale with the model, so half the fp16
+// size plus a fixed 512 MB is added for them. Real use is usually lower.
+// Quantization is only assumed for variants listed in the bundled model
+// list.
+func (m *MLXAdapter) EstimateMemory(params map[string]interface{}) int {
+	if expanded, err := m.applyQualityPreset(params); err == nil {
+		params = expanded
+	}
+	model := strings.ToLower(m.GetStringParameter(params, "model"))
+
+	fp16 := mlxModelSizes[len(mlxModelSizes)-1].mb
+	for _, size := range mlxModelSizes {
+		if strings.Contains(model, size.name) {
+			fp16 = size.mb
+			break
+		}
+	}
+
+	// A quantization parameter only counts for models known to have that
+	// variant, since others fall back to the unquantized weights
+	quantization := ""
+	switch {
+	case strings.HasSuffix(model, "-4bit"):
+		quantization = "4bit"
+	case strings.HasSuffix(model, "-8bit"):
+		quantization = "8bit"
+	default:
+		requested := m.GetStringParameter(params, "quantization")
+		if models, err := bundledModels(); err == nil {
+			for _, info := range models {
+				if strings.EqualFold(info.ID, model) && hasQuantization(&info, requested) {
+					quantization = requested
+				}
+			}
+		}
+	}
+
+	weights := fp16
+	switch quantization {
+	case "4bit":
+		weights = fp16 / 4
+	case "8bit":
+		weights = fp16 / 2
+	}
+	return weights + fp16/2 + 512
+}
+
+// mlxModelInfo records what a Whisper checkpoint can do
+type mlxModelInfo struct {
+	// EnglishOnly models only transcribe English audio
+	EnglishOnly bool
+	// Translate is false for checkpoints not trained on the translate task
+	Translate bool
+}
+
+// mlxModelInfos covers the models offered in the UI; other models fall back
+// to naming conventions in lookupMLXModel
+var mlxModelInfos = map[string]mlxModelInfo{
+	"mlx-community/whisper-large-v3-mlx":      {Translate: true},
+	"mlx-community/whisper-large-v3-mlx-8bit": {Translate: true},
+	"mlx-community/whisper-large-v3-mlx-4bit": {Translate: true},
+	"mlx-community/whisper-base-mlx":          {Translate: true},
+	// Turbo was fine-tuned on transcription data only
+	"mlx-community/whisper-large-v3-turbo": {Translate: false},
+}
+
+// lookupMLXModel returns the capabilities of model. Unknown models are
+// classified by name: ".en" checkpoints and distilled models are
+// English-only, turbo checkpoints cannot translate, anything else is assumed
+// to be a full multilingual model.
+func lookupMLXModel(model string) mlxModelInfo {
+	if info, ok := mlxModelInfos[model]; ok {
+		return info
+	}
+
+	name := strings.ToLower(model)
+	switch {
+	case strings.Contains(name, ".en"), strings.Contains(name, "distil"):
+		return mlxModelInfo{EnglishOnly: true}
+	case strings.Contains(name, "turbo"):
+		return mlxModelInfo{}
+	}
+	return mlxModelInfo{Translate: true}
+}
+
+// SetAllowedModels restricts the model parameter to the given Hugging Face
+// repos, e.g. so tenants cannot load untrusted weights. The model schema
+// offers only these models, defaulting to the first one unless the current
+// default is listed. Any other model is rejected with ErrModelNotAllowed
+// before a subprocess starts, and the OOM fallback skips it. An empty list
+// removes the restriction. Call it before the adapter is used.
+func (m *MLXAdapter) SetAllowedModels(models []string) {
+	m.allowedModels = append([]string(nil), models...)
+	for i := range m.schema {
+		p := &m.schema[i]
+		if p.Name != "model" {
+			continue
+		}
+		if m.modelSchemaDefaults == nil {
+			saved := *p
+			m.modelSchemaDefaults = &saved
+		}
+		p.Options, p.Default = m.modelSchemaDefaults.Options, m.modelSchemaDefaults.Default
+		if len(m.allowedModels) > 0 {
+			p.Options = m.allowedModels
+			if def, _ := p.Default.(string); !m.stringInSlice(def, m.allowedModels) {
+				p.Default = m.allowedModels[0]
+			}
+		}
+	}
+}
+
+// checkModelAllowed rejects a model parameter outside the allowlist. A missing
+// parameter is fine since the schema default is always allowed.
+func (m *MLXAdapter) checkModelAllowed(params map[string]interface{}) error {
+	model := m.GetStringParameter(params, "model")
+	if len(m.allowedModels) == 0 || model == "" || m.stringInSlice(model, m.allowedModels) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrModelNotAllowed, model)
+}
+
+// validateModelTask rejects task and language combinations the selected model
+// cannot handle, which would otherwise produce untranslated or garbled output
+func (m *MLXAdapter) validateModelTask(params map[string]interface{}) error {
+	if err := m.checkModelAllowed(params); err != nil {
+		return err
+	}
+	model := m.GetStringParameter(params, "model")
+	info := lookupMLXModel(model)
+
+	translate := strings.EqualFold(m.GetStringParameter(params, "target_language"), "en") ||
+		m.GetStringParameter(params, "task") == "translate"
+	if translate && !info.Translate {
+		return fmt.Errorf("model %s does not support the translate task", model)
+	}
+
+	if info.EnglishOnly {
+		language := strings.ToLower(m.GetStringParameter(params, "language"))
+		if language != "" && language != "auto" && language != "en" {
+			return fmt.Errorf("model %s is English-only and cannot transcribe language %q", model, language)
+		}
+		target := strings.ToLower(m.GetStringParameter(params, "target_language"))
+		if target != "" && target != "en" {
+			return fmt.Errorf("model %s is English-only and cannot produce language %q", model, target)
+		}
+	}
+	return nil
+}
+
+// ValidateParameters validates params against the schema and checks that the
+// selected model supports the requested task and language
+func (m *MLXAdapter) ValidateParameters(params map[string]interface{}) error {
+	if err := m.BaseAdapter.ValidateParameters(params); err != nil {
+		return err
+	}
+	return m.validateModelTask(params)
+}
@@ -0,0 +1,149 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+)
+
+// ErrInsufficientMemory marks a subprocess failure caused by running out of
+// (unified) memory. Callers can retry with a smaller model.
+var ErrInsufficientMemory = errors.New("MLX ran out of memory")
+
+// mlxModelLadder lists models from largest to smallest; oom_fallback steps
+// down it one rung at a time
+var mlxModelLadder = []string{
+	"mlx-community/whisper-large-v3-mlx",
+	"mlx-community/whisper-large-v3-turbo",
+	"mlx-community/whisper-medium-mlx",
+	"mlx-community/whisper-small-mlx",
+	"mlx-community/whisper-base-mlx",
+	"mlx-community/whisper-tiny-mlx",
+}
+
+// oomMarkers are substrings of Python/Metal errors reported on allocation failure
+var oomMarkers = []string{
+	"out of memory",
+	"outofmemory",
+	"insufficient memory",
+	"memoryerror",
+	"unable to allocate",
+	"failed to allocate",
+}
+
+// modelSizeRung returns the ladder position for model, matching by size name
+// so quantized and other variants of a size land on the same rung
+func modelSizeRung(model string) int {
+	name := strings.ToLower(model)
+	switch {
+	case strings.Contains(name, "turbo"):
+		return 1
+	case strings.Contains(name, "large"):
+		return 0
+	case strings.Contains(name, "medium"):
+		return 2
+	case strings.Contains(name, "small"):
+		return 3
+	case strings.Contains(name, "base"):
+		return 4
+	case strings.Contains(name, "tiny"):
+		return 5
+	}
+	return -1
+}
+
+// smallerModels returns the models below model on the ladder, largest first
+func smallerModels(model string) []string {
+	rung := modelSizeRung(model)
+	if rung < 0 {
+		return nil
+	}
+	return mlxModelLadder[rung+1:]
+}
+
+// isOutOfMemory reports whether a subprocess failure looks like an OOM: an
+// allocation error in its stderr, or a SIGKILL we did not send ourselves
+// (the kernel's OOM killer or macOS memory pressure)
+func isOutOfMemory(ctx context.Context, runErr error, stderrTail string) bool {
+	tail := strings.ToLower(stderrTail)
+	for _, marker := range oomMarkers {
+		if strings.Contains(tail, marker) {
+			return true
+		}
+	}
+
+	var exitErr *exec.ExitError
+	return ctx.Err() == nil && errors.As(runErr, &exitErr) && strings.Contains(exitErr.String(), "signal: killed")
+}
+
+// transcribeWithOOMFallback runs transcribe and, if oom_fallback is set and the
+// run ran out of memory, retries with successively smaller models. Downgrades
+// are recorded in the result metadata.
+func (m *MLXAdapter) transcribeWithOOMFallback(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext, run *mlxRun) (*interfaces.TranscriptResult, error) {
+	result, err := m.transcribe(ctx, input, params, procCtx, run)
+	if err == nil || !errors.Is(err, ErrInsufficientMemory) || !m.GetBoolParameter(params, "oom_fallback") {
+		return result, err
+	}
+
+	resolved, presetErr := m.applyQualityPreset(params)
+	if presetErr != nil {
+		return nil, err
+	}
+	tried := []string{m.GetStringParameter(resolved, "model")}
+
+	for _, candidate := range smallerModels(tried[0]) {
+		retryParams := make(map[string]interface{}, len(resolved)+1)
+		for k, v := range resolved {
+			retryParams[k] = v
+		}
+		retryParams["model"] = candidate
+		if m.validateModelTask(retryParams) != nil {
+			continue
+		}
+
+		logger.Warn("MLX ran out of memory, retrying with a smaller model",
+			"job_id", procCtx.JobID, "failed_model", tried[len(tried)-1], "model", candidate)
+		tried = append(tried, candidate)
+
+		result, err = m.transcribe(ctx, input, retryParams, procCtx, run)
+		if err == nil {
+			result.Metadata["oom_fallback"] = strings.Join(tried, " -> ")
+			return result, nil
+		}
+		if !errors.Is(err, ErrInsufficientMemory) {
+			return result, err
+		}
+	}
+
+	// result is the partial result of the last attempt, if any
+	return result, fmt.Errorf("out of memory with every model tried (%s): %w", strings.Join(tried, ", "), err)
+}
+
+// tailBuffer keeps the last max bytes written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = t.buf[len(t.buf)-t.max:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
@@ -0,0 +1,97 @@
+package adapters
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+)
+
+// ErrPartialResult is returned together with a result that only holds the
+// segments decoded before the subprocess failed. The error also wraps the
+// failure itself.
+var ErrPartialResult = errors.New("MLX transcription incomplete")
+
+// classifyRunError wraps a failed subprocess run with the sentinel matching
+// its stderr, so callers can tell bad input from model and runtime errors
+func (m *MLXAdapter) classifyRunError(ctx context.Context, err error, stderrTail, audioPath, modelName, jobID string) error {
+	if ctx.Err() != nil {
+		logger.Info("MLX execution cancelled", "job_id", jobID)
+		return fmt.Errorf("MLX transcription cancelled: %w", ctx.Err())
+	}
+	logger.Error("MLX execution failed", "job_id", jobID, "error", err)
+	if isOutOfMemory(ctx, err, stderrTail) {
+		return fmt.Errorf("MLX execution failed: %w: %w", ErrInsufficientMemory, err)
+	}
+	if isAudioDecodeError(stderrTail) {
+		return fmt.Errorf("MLX execution failed: %w: %s: %w", ErrAudioDecode, audioPath, err)
+	}
+	if isModelNotFound(stderrTail) {
+		return fmt.Errorf("MLX execution failed: %w: %s: %w", ErrModelNotFound, modelName, err)
+	}
+	if isTransient(stderrTail) {
+		return fmt.Errorf("MLX execution failed: %w: %w", errMLXTransient, err)
+	}
+	return fmt.Errorf("MLX execution failed: %w", err)
+}
+
+// recoverPartialResult builds a result from the segments the Python bridge
+// appended to path before it died, post-processed like a complete result. A
+// line cut short by the crash is ignored. chunks are the job's chunks, as
+// for parseResult. It returns nil if no segment was written.
+func (m *MLXAdapter) recoverPartialResult(path string, params map[string]interface{}, chunks [][2]float64) *interfaces.TranscriptResult {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var segments []json.RawMessage
+	var text strings.Builder
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var seg struct {
+			Text string `json:"text"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &seg) != nil {
+			continue
+		}
+		segments = append(segments, json.RawMessage(append([]byte(nil), scanner.Bytes()...)))
+		text.WriteString(seg.Text)
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+
+	// Reuse parseResult by writing the segments out as a complete output
+	language := normalizeLanguage(m.GetStringParameter(params, "language"))
+	if language == "auto" {
+		language = ""
+	}
+	output := map[string]interface{}{
+		"text":     text.String(),
+		"segments": segments,
+		"language": language,
+	}
+	outputPath := path + ".json"
+	if err := writeJSONFile(outputPath, output); err != nil {
+		logger.Warn("Failed to write recovered MLX segments", "path", outputPath, "error", err)
+		return nil
+	}
+	result, err := m.parseResult(outputPath, params, chunks)
+	if err != nil {
+		logger.Warn("Failed to parse recovered MLX segments", "path", outputPath, "error", err)
+		return nil
+	}
+	result.Partial = true
+	result.Metadata["partial_segments"] = strconv.Itoa(len(segments))
+	return result
+}
@@ -0,0 +1,404 @@
+package adapters
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// Post-processing helpers applied to parsed MLX results
+
+// timestampDecimals maps a timestamp_precision value to a number of decimal
+// places. Full precision is reported as -1.
+func timestampDecimals(precision string) (int, error) {
+	switch precision {
+	case "", "full":
+		return -1, nil
+	case "ms":
+		return 3, nil
+	case "cs":
+		return 2, nil
+	case "ds":
+		return 1, nil
+	case "s":
+		return 0, nil
+	}
+
+	decimals, err := strconv.Atoi(precision)
+	if err != nil || decimals < 0 || decimals > 6 {
+		return 0, fmt.Errorf("invalid timestamp_precision %q: expected full, ms, cs, ds, s or 0-6 decimal places", precision)
+	}
+	return decimals, nil
+}
+
+// mapTimestamps replaces every timestamp in the result with fn(timestamp).
+// Words are visited through their segments; WordSegments is expected to be
+// rebuilt from them afterwards (see flattenWords).
+func mapTimestamps(result *interfaces.TranscriptResult, fn func(float64) float64) {
+	for i := range result.Segments {
+		seg := &result.Segments[i]
+		seg.Start = fn(seg.Start)
+		seg.End = fn(seg.End)
+		for j := range seg.Words {
+			word := &seg.Words[j]
+			word.Start = fn(word.Start)
+			word.End = fn(word.End)
+			for k := range word.Chars {
+				word.Chars[k].Start = fn(word.Chars[k].Start)
+				word.Chars[k].End = fn(word.Chars[k].End)
+			}
+		}
+	}
+	for i := range result.TurnBoundaries {
+		result.TurnBoundaries[i].Time = fn(result.TurnBoundaries[i].Time)
+	}
+}
+
+// flattenWords rebuilds WordSegments from the per-segment word timings
+func flattenWords(result *interfaces.TranscriptResult) {
+	result.WordSegments = nil
+	for _, seg := range result.Segments {
+		result.WordSegments = append(result.WordSegments, seg.Words...)
+	}
+}
+
+// mergeChunks removes the duplicates that overlapping chunks produce. Each
+// overlap is split at its midpoint: a segment is kept only from the chunk
+// whose side of the split its midpoint falls on. Text is rebuilt from the
+// remaining segments, joined as usual for the result's language.
+func mergeChunks(result *interfaces.TranscriptResult, chunks [][2]float64) {
+	kept := result.Segments[:0]
+	for _, seg := range result.Segments {
+		if seg.RangeIndex == nil {
+			continue
+		}
+		i := *seg.RangeIndex
+		mid := (seg.Start + seg.End) / 2
+		if i > 0 && mid < (chunks[i][0]+chunks[i-1][1])/2 {
+			continue
+		}
+		if i < len(chunks)-1 && mid >= (chunks[i+1][0]+chunks[i][1])/2 {
+			continue
+		}
+		seg.RangeIndex = nil
+		kept = append(kept, seg)
+	}
+	result.Segments = kept
+	result.Text = joinSegmentText(kept, result.Language)
+	flattenWords(result)
+}
+
+// trimLowConfidenceTails strips trailing words with a probability below
+// threshold from each segment, which is where Whisper tends to hallucinate,
+// and ends the segment at its last remaining word. The segment text is
+// rebuilt from the remaining words. Segments whose words all fall below the
+// threshold are dropped; segments without word timings, and words without a
+// probability, are kept. It reports whether anything was removed.
+func trimLowConfidenceTails(segments []interfaces.TranscriptSegment, threshold float64, language string) ([]interfaces.TranscriptSegment, bool) {
+	sep := " "
+	if unspacedLanguages[strings.ToLower(language)] {
+		sep = ""
+	}
+
+	kept := segments[:0]
+	trimmed := false
+	for _, seg := range segments {
+		n := len(seg.Words)
+		for n > 0 && seg.Words[n-1].Probability != nil && *seg.Words[n-1].Probability < threshold {
+			n--
+		}
+		if n == len(seg.Words) {
+			kept = append(kept, seg)
+			continue
+		}
+		trimmed = true
+		if n == 0 {
+			continue
+		}
+		seg.Words = seg.Words[:n]
+		seg.End = seg.Words[n-1].End
+		texts := make([]string, n)
+		for i, w := range seg.Words {
+			texts[i] = w.Word
+		}
+		seg.Text = strings.Join(texts, sep)
+		kept = append(kept, seg)
+	}
+	return kept, trimmed
+}
+
+// collapseRepetitions finds runs of at least minRun consecutive segments with
+// near-identical text (equal after case folding and dropping punctuation),
+// which Whisper produces when it loops on music or silence. A run whose
+// segments all have a no-speech probability above noSpeechThreshold is
+// dropped; otherwise only its first segment is kept. It returns the kept
+// segments and the number removed.
+func collapseRepetitions(segments []interfaces.TranscriptSegment, minRun int, noSpeechThreshold float64) ([]interfaces.TranscriptSegment, int) {
+	kept := make([]interfaces.TranscriptSegment, 0, len(segments))
+	removed := 0
+	for i := 0; i < len(segments); {
+		key := repetitionKey(segments[i].Text)
+		j := i + 1
+		for j < len(segments) && key != "" && repetitionKey(segments[j].Text) == key {
+			j++
+		}
+		if j-i < minRun {
+			kept = append(kept, segments[i:j]...)
+			i = j
+			continue
+		}
+
+		silent := true
+		for _, seg := range segments[i:j] {
+			if seg.NoSpeechProb == nil || *seg.NoSpeechProb <= noSpeechThreshold {
+				silent = false
+				break
+			}
+		}
+		if silent {
+			removed += j - i
+		} else {
+			kept = append(kept, segments[i])
+			removed += j - i - 1
+		}
+		i = j
+	}
+	return kept, removed
+}
+
+// repetitionKey reduces text to lower-case letters and digits separated by
+// single spaces, so that segments differing only in case, punctuation or
+// spacing compare equal
+func repetitionKey(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// mergeShortSegments merges each segment shorter than minDuration seconds
+// into the segment after it (the last one into the one before), joining text
+// and words and widening the time range. Segments are never merged across a
+// silence longer than maxGap seconds or across ranges. The surviving segment
+// keeps its own decoder statistics.
+func mergeShortSegments(segments []interfaces.TranscriptSegment, minDuration, maxGap float64, language string) []interfaces.TranscriptSegment {
+	sep := " "
+	if unspacedLanguages[strings.ToLower(language)] {
+		sep = ""
+	}
+	join := func(a, b string) string {
+		if a == "" || b == "" {
+			return a + b
+		}
+		return a + sep + b
+	}
+	mergeable := func(first, second interfaces.TranscriptSegment) bool {
+		sameRange := (first.RangeIndex == nil) == (second.RangeIndex == nil) &&
+			(first.RangeIndex == nil || *first.RangeIndex == *second.RangeIndex)
+		return sameRange && second.Start-first.End <= maxGap
+	}
+	// merge combines first into second, keeping second's other fields
+	merge := func(first, second interfaces.TranscriptSegment) interfaces.TranscriptSegment {
+		second.Start = math.Min(first.Start, second.Start)
+		second.End = math.Max(first.End, second.End)
+		second.Text = join(first.Text, second.Text)
+		second.NormalizedText = join(first.NormalizedText, second.NormalizedText)
+		second.Words = append(append([]interfaces.WordTiming(nil), first.Words...), second.Words...)
+		return second
+	}
+
+	merged := make([]interfaces.TranscriptSegment, 0, len(segments))
+	var carry *interfaces.TranscriptSegment
+	for _, seg := range segments {
+		if carry != nil {
+			if mergeable(*carry, seg) {
+				seg = merge(*carry, seg)
+			} else {
+				merged = append(merged, *carry)
+			}
+			carry = nil
+		}
+		if seg.End-seg.Start < minDuration {
+			s := seg
+			carry = &s
+			continue
+		}
+		merged = append(merged, seg)
+	}
+
+	if carry != nil {
+		if n := len(merged); n > 0 && mergeable(merged[n-1], *carry) {
+			last := &merged[n-1]
+			last.End = math.Max(last.End, carry.End)
+			last.Text = join(last.Text, carry.Text)
+			last.NormalizedText = join(last.NormalizedText, carry.NormalizedText)
+			last.Words = append(last.Words, carry.Words...)
+		} else {
+			merged = append(merged, *carry)
+		}
+	}
+	return merged
+}
+
+// roundTimestamps rounds every timestamp in the result to the given number of
+// decimal places so that all writers serialize the same values.
+func roundTimestamps(result *interfaces.TranscriptResult, decimals int) {
+	if decimals < 0 {
+		return
+	}
+
+	scale := math.Pow10(decimals)
+	mapTimestamps(result, func(v float64) float64 { return math.Round(v*scale) / scale })
+}
+
+// repairWordTimings clamps word timings into the bounds of their segment and
+// makes them monotonic in spoken order, so that clients which assume words are
+// contained in (and ordered within) their segment never see out-of-range times.
+// Word order is preserved; only the times are adjusted. It reports whether any
+// time had to change.
+func repairWordTimings(seg interfaces.TranscriptSegment, words []interfaces.TranscriptWord) bool {
+	upper := math.Max(seg.End, seg.Start)
+	clamp := func(v float64) float64 {
+		return math.Min(math.Max(v, seg.Start), upper)
+	}
+
+	prevEnd := seg.Start
+	changed := false
+	for i := range words {
+		start := clamp(words[i].Start)
+		end := clamp(words[i].End)
+
+		if start < prevEnd {
+			start = prevEnd
+		}
+		if end < start {
+			end = start
+		}
+
+		if start != words[i].Start || end != words[i].End {
+			changed = true
+		}
+		words[i].Start = start
+		words[i].End = end
+		prevEnd = end
+	}
+	return changed
+}
+
+// wordTimingsValid reports whether words lie within their segment, in order
+// and without overlapping, i.e. whether repairWordTimings would leave them
+// unchanged
+func wordTimingsValid(seg interfaces.TranscriptSegment, words []interfaces.TranscriptWord) bool {
+	repaired := append([]interfaces.TranscriptWord(nil), words...)
+	return !repairWordTimings(seg, repaired)
+}
+
+// interpolateCharTimings splits a word's time span evenly across its characters.
+// Whisper has no notion of character (or phoneme) timing, so these are only an
+// approximation proportional to character count.
+func interpolateCharTimings(word interfaces.TranscriptWord) []interfaces.CharTiming {
+	chars := []rune(word.Word)
+	if len(chars) == 0 {
+		return nil
+	}
+
+	step := (word.End - word.Start) / float64(len(chars))
+	timings := make([]interfaces.CharTiming, len(chars))
+	for i, c := range chars {
+		timings[i] = interfaces.CharTiming{
+			Char:  string(c),
+			Start: word.Start + step*float64(i),
+			End:   word.Start + step*float64(i+1),
+		}
+	}
+	// Avoid floating point drift on the last character
+	timings[len(timings)-1].End = word.End
+
+	return timings
+}
+
+// shiftTimestamps adds offset seconds to every time in the result, mapping
+// times relative to an extracted excerpt back onto the original audio
+func shiftTimestamps(result *interfaces.TranscriptResult, offset float64) {
+	mapTimestamps(result, func(v float64) float64 { return v + offset })
+}
+
+// transcriptMetrics computes throughput for a finished run. The audio duration
+// comes from the input if known, otherwise from the end of the last segment,
+// less the time_offset that was added to it.
+func transcriptMetrics(result *interfaces.TranscriptResult, input interfaces.AudioInput, elapsed time.Duration, timeOffset float64) *interfaces.TranscriptMetrics {
+	metrics := &interfaces.TranscriptMetrics{
+		ProcessingDuration: elapsed,
+		AudioDuration:      input.Duration,
+	}
+	if metrics.AudioDuration <= 0 && len(result.Segments) > 0 {
+		last := result.Segments[len(result.Segments)-1].End - timeOffset
+		metrics.AudioDuration = time.Duration(last * float64(time.Second))
+	}
+	if metrics.AudioDuration > 0 {
+		metrics.RealTimeFactor = elapsed.Seconds() / metrics.AudioDuration.Seconds()
+	}
+	return metrics
+}
+
+// parseRecordingStart parses a recording_start_time value. An empty value
+// returns a zero time.
+func parseRecordingStart(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	start, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid recording_start_time %q: expected RFC3339: %w", value, err)
+	}
+	return start, nil
+}
+
+// applyWallClock sets absolute start and end times on every segment from the
+// wall-clock time at which the recording started
+func applyWallClock(result *interfaces.TranscriptResult, recordingStart time.Time) {
+	at := func(offset float64) *time.Time {
+		t := recordingStart.Add(time.Duration(offset * float64(time.Second)))
+		return &t
+	}
+	for i := range result.Segments {
+		result.Segments[i].StartTime = at(result.Segments[i].Start)
+		result.Segments[i].EndTime = at(result.Segments[i].End)
+	}
+}
+
+// unspacedLanguages are languages whose script does not separate words (or
+// sentences) with spaces, so segments are joined without one
+var unspacedLanguages = map[string]bool{
+	"zh":  true,
+	"yue": true,
+	"ja":  true,
+	"th":  true,
+	"lo":  true,
+	"my":  true,
+	"km":  true,
+	"bo":  true,
+}
+
+// joinSegmentText rebuilds transcript text from segments. Segments are joined
+// with a space unless language is one written without spaces; pass an empty
+// language to always use spaces.
+func joinSegmentText(segments []interfaces.TranscriptSegment, language string) string {
+	sep := " "
+	if unspacedLanguages[strings.ToLower(language)] {
+		sep = ""
+	}
+
+	texts := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		if text := strings.TrimSpace(seg.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return strings.Join(texts, sep)
+}
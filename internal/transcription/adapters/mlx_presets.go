@@ -0,0 +1,76 @@
+package adapters
+
+import (
+	"fmt"
+)
+
+// mlxQualityPresets bundles coherent model and decoding settings per quality
+// tier. Parameters supplied explicitly by the caller always take precedence.
+var mlxQualityPresets = map[string]map[string]interface{}{
+	// draft favours speed: a small model, greedy decoding with no temperature
+	// fallback, and no word alignment pass
+	"draft": {
+		"model":           "mlx-community/whisper-base-mlx",
+		"quantization":    "4bit",
+		"beam_size":       1,
+		"temperature":     0.0,
+		"word_timestamps": false,
+	},
+	// balanced keeps Whisper's default fallback schedule on the turbo model
+	"balanced": {
+		"model":           "mlx-community/whisper-large-v3-turbo",
+		"quantization":    "4bit",
+		"beam_size":       1,
+		"word_timestamps": true,
+	},
+	// accurate uses the full-precision large-v3 weights with the fallback
+	// schedule. mlx-whisper only implements greedy decoding, so the beam size
+	// stays at 1 here as well.
+	"accurate": {
+		"model":           "mlx-community/whisper-large-v3-mlx",
+		"quantization":    "none",
+		"beam_size":       1,
+		"word_timestamps": true,
+	},
+}
+
+// mlxFastPreset is what the "fast" parameter selects: the turbo model with
+// greedy decoding, no temperature fallback and no conditioning on previous
+// text, which also avoids the slow repetition loops that conditioning causes
+var mlxFastPreset = map[string]interface{}{
+	"model":                      "mlx-community/whisper-large-v3-turbo",
+	"beam_size":                  1,
+	"temperature":                0.0,
+	"condition_on_previous_text": false,
+}
+
+// applyQualityPreset expands the "quality" and "fast" parameters into their
+// bundles of settings, fast taking precedence. It returns a new map;
+// explicit parameters are kept as given.
+func (m *MLXAdapter) applyQualityPreset(params map[string]interface{}) (map[string]interface{}, error) {
+	quality := m.GetStringParameter(params, "quality")
+	fast := m.GetBoolParameter(params, "fast")
+	if quality == "" && !fast {
+		return params, nil
+	}
+
+	var presets []map[string]interface{}
+	if quality != "" {
+		preset, ok := mlxQualityPresets[quality]
+		if !ok {
+			return nil, fmt.Errorf("unknown quality preset %q: expected draft, balanced or accurate", quality)
+		}
+		presets = append(presets, preset)
+	}
+	if fast {
+		presets = append(presets, mlxFastPreset)
+	}
+
+	resolved := make(map[string]interface{}, len(params)+len(mlxFastPreset))
+	for _, preset := range append(presets, params) {
+		for k, v := range preset {
+			resolved[k] = v
+		}
+	}
+	return resolved, nil
+}
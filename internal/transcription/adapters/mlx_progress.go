@@ -0,0 +1,124 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// progressEvent is one JSON Lines record on the progress channel. The Python
+// script writes "progress" events in the same format.
+type progressEvent struct {
+	Time     time.Time `json:"time"`
+	JobID    string    `json:"job_id"`
+	Event    string    `json:"event"` // "started", "progress", "completed" or "failed"
+	Progress *float64  `json:"progress,omitempty"`
+	Message  string    `json:"message,omitempty"`
+}
+
+// mlxRun carries optional per-run outputs through a transcription
+type mlxRun struct {
+	jobID string
+	dump  *mlxDebugDump
+
+	// progress receives JSON Lines events; it is also handed to the
+	// subprocess, which writes to it while the Go side is waiting
+	progress   *os.File
+	progressMu sync.Mutex
+
+	// stream receives segments for TranscribeStream
+	stream *segmentStream
+
+	// batch lists the inputs of a TranscribeBatch run
+	batch []*batchItem
+}
+
+// openProgress opens the progress channel requested in procCtx, if any.
+// ProgressFD is reopened through /dev/fd so the caller's descriptor is never
+// closed by us.
+func openProgress(procCtx interfaces.ProcessingContext) (*os.File, error) {
+	path := procCtx.ProgressPipe
+	if procCtx.ProgressFD > 0 {
+		path = fmt.Sprintf("/dev/fd/%d", procCtx.ProgressFD)
+	}
+	if path == "" {
+		return nil, nil
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+}
+
+// emit writes an event if a progress channel is open. Write errors are
+// ignored: a consumer going away must not fail the job.
+func (r *mlxRun) emit(event, message string) {
+	if r.progress == nil {
+		return
+	}
+	data, err := json.Marshal(progressEvent{Time: time.Now().UTC(), JobID: r.jobID, Event: event, Message: message})
+	if err != nil {
+		return
+	}
+
+	r.progressMu.Lock()
+	defer r.progressMu.Unlock()
+	r.progress.Write(append(data, '\n'))
+}
+
+// outputLineWriter passes subprocess output through to out and turns each
+// line into a structured log event: "PROGRESS <fraction> [message]" and
+// "INFO <message>" lines are logged at info, anything else at debug.
+// Progress lines are also reported to progress, and the JSON of
+// "SEGMENT <json>" lines is passed to segment, if set.
+type outputLineWriter struct {
+	out      io.Writer
+	progress func(fraction float64, message string)
+	segment  func(data string)
+	log      func(level, msg string, fields map[string]any)
+	partial  []byte
+}
+
+func (w *outputLineWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.scanLine(string(w.partial[:i]))
+		w.partial = w.partial[i+1:]
+	}
+	return w.out.Write(p)
+}
+
+func (w *outputLineWriter) scanLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	if rest, ok := strings.CutPrefix(line, "PROGRESS "); ok {
+		value, message, _ := strings.Cut(rest, " ")
+		if fraction, err := strconv.ParseFloat(value, 64); err == nil {
+			if w.progress != nil {
+				w.progress(fraction, message)
+			}
+			w.log("info", "MLX progress", map[string]any{"source": "mlx", "progress": fraction, "message": message})
+			return
+		}
+	}
+	if data, ok := strings.CutPrefix(line, "SEGMENT "); ok && w.segment != nil {
+		w.segment(data)
+		return
+	}
+	if message, ok := strings.CutPrefix(line, "INFO "); ok {
+		w.log("info", message, map[string]any{"source": "mlx"})
+		return
+	}
+	w.log("debug", line, map[string]any{"source": "mlx"})
+}
@@ -0,0 +1,25 @@
+# Quantization-aware model downloads, shared by the MLX transcription bridge
+# and the download helper scripts
+QUANTIZATION_TAGS = ("4bit", "8bit")
+
+def select_quantization(files, quantization):
+    # Returns the files to fetch for one variant and the directory holding it
+    import os
+    weights = [f for f in files if f.endswith((".safetensors", ".npz"))]
+    if quantization in QUANTIZATION_TAGS:
+        chosen = [f for f in weights if quantization in f]
+    else:
+        chosen = [f for f in weights if not any(tag in f for tag in QUANTIZATION_TAGS)]
+    if not chosen:
+        # The repository holds a single variant; take all of it
+        chosen = weights
+    subdir = os.path.dirname(chosen[0]) if chosen else ""
+    configs = [f for f in files if f.endswith(".json") and os.path.dirname(f) in ("", subdir)]
+    return configs + chosen, subdir
+
+def download_quantization(repo_id, quantization):
+    import os
+    from huggingface_hub import list_repo_files, snapshot_download
+    selected, subdir = select_quantization(list_repo_files(repo_id), quantization)
+    path = snapshot_download(repo_id=repo_id, allow_patterns=selected)
+    return os.path.join(path, subdir) if subdir else path
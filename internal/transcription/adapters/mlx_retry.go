@@ -0,0 +1,79 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+)
+
+// RetryPolicy controls how often a transcription is retried after a
+// transient subprocess failure. The delay doubles after each attempt.
+type RetryPolicy struct {
+	// Attempts is the total number of tries; 1 or less disables retries
+	Attempts  int
+	BaseDelay time.Duration
+}
+
+// defaultMLXRetryPolicy is used until SetRetryPolicy is called
+var defaultMLXRetryPolicy = RetryPolicy{Attempts: 3, BaseDelay: 2 * time.Second}
+
+// errMLXTransient marks a subprocess failure that is likely to succeed if
+// retried, such as uv cache lock contention or a network timeout
+var errMLXTransient = errors.New("transient MLX failure")
+
+// transientMarkers are substrings of uv and huggingface_hub errors that
+// indicate a temporary condition rather than a problem with the job
+var transientMarkers = []string{
+	"failed to acquire lock",
+	"resource temporarily unavailable",
+	"timed out",
+	"connecttimeout",
+	"readtimeout",
+	"connectionerror",
+	"connection reset",
+	"temporary failure in name resolution",
+	"503 server error",
+	"502 server error",
+}
+
+// isTransient reports whether subprocess stderr shows a temporary failure
+func isTransient(stderrTail string) bool {
+	tail := strings.ToLower(stderrTail)
+	for _, marker := range transientMarkers {
+		if strings.Contains(tail, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRetryPolicy replaces the retry policy for transient failures. Pass a
+// zero RetryPolicy to disable retries.
+func (m *MLXAdapter) SetRetryPolicy(policy RetryPolicy) {
+	m.retryPolicy = policy
+}
+
+// transcribeWithRetry runs transcribeWithOOMFallback, retrying failures
+// classified as transient with exponential backoff. Waiting between attempts
+// stops as soon as ctx is done.
+func (m *MLXAdapter) transcribeWithRetry(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext, run *mlxRun) (*interfaces.TranscriptResult, error) {
+	delay := m.retryPolicy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		result, err := m.transcribeWithOOMFallback(ctx, input, params, procCtx, run)
+		if err == nil || !errors.Is(err, errMLXTransient) || attempt >= m.retryPolicy.Attempts {
+			return result, err
+		}
+
+		logger.Warn("Transient MLX failure, retrying", "job_id", procCtx.JobID, "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return result, err
+		}
+		delay *= 2
+	}
+}
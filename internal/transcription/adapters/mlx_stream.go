@@ -0,0 +1,137 @@
+package adapters
+
+import (
+	"context"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+// TranscribeChunks transcribes the input and passes the result to send as a
+// sequence of size-limited chunks, e.g. for a server-streaming RPC. It stops
+// at the first error returned by send.
+func (m *MLXAdapter) TranscribeChunks(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext, opts interfaces.ChunkOptions, send func(interfaces.TranscriptChunk) error) error {
+	result, err := m.Transcribe(ctx, input, params, procCtx)
+	if err != nil {
+		return err
+	}
+
+	chunks, err := interfaces.ChunkResult(result, opts)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TranscribeStream transcribes the input like Transcribe, sending segments on
+// the first channel as the model produces them so that a live view does not
+// wait for the whole file. Both channels are closed when the job ends; the
+// error channel receives at most one error first. Cancelling ctx kills the
+// subprocess.
+//
+// Segments are sent live unless a setting rewrites the segment list after
+// decoding (chunk_length, min_segment_duration, target_language other than
+// "en", or a SegmentAnalyzer); then they are all sent when the job finishes.
+// Live segments carry no normalized_text, and if a retry switches to a
+// smaller model the segments already sent are not sent again.
+func (m *MLXAdapter) TranscribeStream(ctx context.Context, input interfaces.AudioInput, params map[string]interface{}, procCtx interfaces.ProcessingContext) (<-chan interfaces.TranscriptSegment, <-chan error) {
+	segments := make(chan interfaces.TranscriptSegment, 16)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(segments)
+
+		stream := &segmentStream{ctx: ctx, out: segments}
+		result, err := m.transcribeJob(ctx, input, params, procCtx, stream)
+		if err == nil {
+			// Send what was not streamed live: everything after a cache hit
+			// or a non-live run, otherwise nothing
+			for i := stream.delivered; i < len(result.Segments); i++ {
+				if !stream.deliver(result.Segments[i]) {
+					break
+				}
+			}
+			err = ctx.Err()
+		}
+		if err != nil {
+			errs <- err
+		}
+	}()
+	return segments, errs
+}
+
+// segmentStream sends segments to a TranscribeStream caller. Segments are
+// counted per subprocess attempt, so an attempt that is retried after a
+// failure skips the segments an earlier attempt already sent.
+type segmentStream struct {
+	ctx       context.Context
+	out       chan<- interfaces.TranscriptSegment
+	delivered int
+	seen      int
+}
+
+// restart starts counting a new attempt
+func (s *segmentStream) restart() {
+	s.seen = 0
+}
+
+// send delivers the next segment of the current attempt unless it was sent
+// before
+func (s *segmentStream) send(seg interfaces.TranscriptSegment) {
+	s.seen++
+	if s.seen > s.delivered {
+		s.deliver(seg)
+	}
+}
+
+// deliver sends seg, giving up if ctx is cancelled
+func (s *segmentStream) deliver(seg interfaces.TranscriptSegment) bool {
+	select {
+	case s.out <- seg:
+		s.delivered++
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// finishStreamSegment applies the per-segment steps of parseResult and
+// transcribe to a live segment. It reports false for a segment that
+// word_confidence_threshold drops.
+func (m *MLXAdapter) finishStreamSegment(raw mlxSegment, params map[string]interface{}, language string, recordingStart time.Time) (interfaces.TranscriptSegment, bool) {
+	if language == "auto" {
+		language = ""
+	}
+	seg, _ := m.convertSegment(raw, params)
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{seg},
+	}
+	if threshold := m.GetFloatParameter(params, "word_confidence_threshold"); threshold > 0 {
+		result.Segments, _ = trimLowConfidenceTails(result.Segments, threshold, language)
+		if len(result.Segments) == 0 {
+			return interfaces.TranscriptSegment{}, false
+		}
+	}
+	if offset := m.GetFloatParameter(params, "start_from"); offset > 0 {
+		shiftTimestamps(result, offset)
+	}
+	if offset := m.GetFloatParameter(params, "time_offset"); offset > 0 {
+		shiftTimestamps(result, offset)
+	}
+	if decimals, err := timestampDecimals(m.GetStringParameter(params, "timestamp_precision")); err == nil {
+		roundTimestamps(result, decimals)
+	}
+	if !recordingStart.IsZero() {
+		applyWallClock(result, recordingStart)
+	}
+	return result.Segments[0], true
+}
@@ -0,0 +1,122 @@
+package adapters
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+)
+
+// SetTempDirectory tells the adapter which ProcessingContext.TempDirectory
+// its jobs use, so that CleanupStaleTempDirs knows where to look. If
+// olderThan is positive, PrepareEnvironment also cleans up directories older
+// than that.
+func (m *MLXAdapter) SetTempDirectory(dir string, olderThan time.Duration) {
+	m.tempRoot = dir
+	m.staleTempAge = olderThan
+}
+
+// tempDirLockFile holds the PID of the process using a temp directory
+const tempDirLockFile = ".scriberr.lock"
+
+// CreateTempDirectory creates a job temp directory like
+// BaseAdapter.CreateTempDirectory and locks it: the lock file marks it as in
+// use by this process, so that CleanupStaleTempDirs leaves it alone while we
+// are alive.
+func (m *MLXAdapter) CreateTempDirectory(procCtx interfaces.ProcessingContext) (string, error) {
+	tempDir, err := m.BaseAdapter.CreateTempDirectory(procCtx)
+	if err != nil {
+		return "", err
+	}
+	lock := filepath.Join(tempDir, tempDirLockFile)
+	if err := os.WriteFile(lock, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		return "", fmt.Errorf("failed to lock temp directory: %w", err)
+	}
+	return tempDir, nil
+}
+
+// unlockTempDirectory removes the lock from a temp directory that is kept
+// after its job, so CleanupStaleTempDirs can remove it once it is old enough
+func unlockTempDirectory(dir string) {
+	if err := os.Remove(filepath.Join(dir, tempDirLockFile)); err != nil && !os.IsNotExist(err) {
+		logger.Warn("Failed to unlock MLX temp directory", "dir", dir, "error", err)
+	}
+}
+
+// CleanupStaleTempDirs removes job temp directories (including spooled and
+// transcoded input) left behind by runs that were killed, and returns how
+// many it removed. A directory is removed if it was last modified more than
+// olderThan ago and is not in use: it has no lock file, or its lock names a
+// process that no longer exists.
+func (m *MLXAdapter) CleanupStaleTempDirs(olderThan time.Duration) (int, error) {
+	if m.tempRoot == "" {
+		return 0, errors.New("no temp directory configured; call SetTempDirectory first")
+	}
+	root := filepath.Join(m.tempRoot, m.modelID)
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan temp directory: %w", err)
+	}
+
+	active := make(map[string]bool)
+	m.jobsMu.Lock()
+	for _, dir := range m.activeJobs {
+		active[dir] = true
+	}
+	m.jobsMu.Unlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) || active[dir] || tempDirInUse(dir) {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// tempDirInUse reports whether the process named in dir's lock file is still
+// running. Directories without a readable lock are not in use.
+func tempDirInUse(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, tempDirLockFile))
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return err == nil && processAlive(pid)
+}
+
+// cleanupStaleTempDirsOnPrepare runs the cleanup requested with
+// SetTempDirectory. Failures are logged; they do not stop the adapter.
+func (m *MLXAdapter) cleanupStaleTempDirsOnPrepare() {
+	if m.tempRoot == "" || m.staleTempAge <= 0 {
+		return
+	}
+	removed, err := m.CleanupStaleTempDirs(m.staleTempAge)
+	if err != nil {
+		logger.Warn("Failed to clean up stale MLX temp directories", "error", err)
+	}
+	if removed > 0 {
+		logger.Info("Removed stale MLX temp directories", "count", removed)
+	}
+}
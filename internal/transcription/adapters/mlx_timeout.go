@@ -0,0 +1,37 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is returned when a transcription runs past its deadline, either
+// the adapter's maximum duration or the caller's context deadline
+var ErrTimeout = errors.New("MLX transcription timed out")
+
+// SetMaxDuration caps how long a single Transcribe call may run, whatever
+// the caller's context allows. The subprocess tree is killed when the limit
+// is reached. Zero (the default) means no limit.
+func (m *MLXAdapter) SetMaxDuration(d time.Duration) {
+	m.maxDuration = d
+}
+
+// withMaxDuration derives the job context, which ends at the earlier of the
+// caller's deadline and the maximum duration
+func (m *MLXAdapter) withMaxDuration(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.maxDuration <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, m.maxDuration)
+}
+
+// timeoutError wraps err in ErrTimeout if the job context's deadline passed,
+// recording how long the job ran and with which model
+func timeoutError(ctx context.Context, err error, elapsed time.Duration, model string) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w after %s with model %s: %w", ErrTimeout, elapsed.Round(time.Millisecond), model, err)
+}
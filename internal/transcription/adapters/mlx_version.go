@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// mlxVersionScript prints the installed mlx-whisper package version
+const mlxVersionScript = `
+from importlib.metadata import version
+print(version("mlx-whisper"))
+`
+
+// MLXWhisperVersion reports the mlx-whisper version installed in the adapter's
+// environment. The value is cached until the environment is prepared again.
+func (m *MLXAdapter) MLXWhisperVersion(ctx context.Context) (string, error) {
+	m.stateMu.RLock()
+	cached := m.mlxWhisperVersion
+	m.stateMu.RUnlock()
+	if cached != "" {
+		return cached, nil
+	}
+
+	mlxPath := filepath.Join(m.envPath, "MLX")
+	cmd := exec.CommandContext(ctx, "uv", "run", "--project", mlxPath, "python", "-c", mlxVersionScript)
+	out, err := m.commandOutput(ctx, cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to query mlx-whisper version: %w", err)
+	}
+	version := lastLine(string(out))
+
+	m.stateMu.Lock()
+	m.mlxWhisperVersion = version
+	m.stateMu.Unlock()
+	return version, nil
+}
+
+// VersionInfo returns the adapter version and, if it can be determined, the
+// installed mlx-whisper version
+func (m *MLXAdapter) VersionInfo(ctx context.Context) map[string]string {
+	info := map[string]string{"adapter_version": m.GetCapabilities().Version}
+	if version, err := m.MLXWhisperVersion(ctx); err == nil && strings.TrimSpace(version) != "" {
+		info["mlx_whisper_version"] = version
+	}
+	return info
+}
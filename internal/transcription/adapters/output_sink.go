@@ -0,0 +1,48 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"scriberr/internal/transcription/interfaces"
+	"scriberr/pkg/logger"
+)
+
+// putResult marshals the result and stores it in the context's output sink
+func putResult(procCtx interfaces.ProcessingContext, name string, result *interfaces.TranscriptResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	if err := procCtx.OutputSink.Put(name, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write %s to output sink: %w", name, err)
+	}
+	return nil
+}
+
+// putLogFiles copies closed log files to the context's output sink. Logs are
+// best effort: failures are logged and do not fail the job.
+func putLogFiles(procCtx interfaces.ProcessingContext, files []*os.File) {
+	for _, f := range files {
+		name := filepath.Base(f.Name())
+		data, err := os.Open(f.Name())
+		if err != nil {
+			logger.Warn("Failed to read log for output sink", "job_id", procCtx.JobID, "file", name, "error", err)
+			continue
+		}
+		if err := procCtx.OutputSink.Put(name, data); err != nil {
+			logger.Warn("Failed to write log to output sink", "job_id", procCtx.JobID, "file", name, "error", err)
+		}
+		data.Close()
+	}
+}
+
+// closeFiles closes every file, ignoring errors (including double closes)
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
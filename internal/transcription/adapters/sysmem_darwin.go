@@ -0,0 +1,23 @@
+//go:build darwin
+// +build darwin
+
+package adapters
+
+import (
+	"encoding/binary"
+	"syscall"
+)
+
+// totalMemoryBytes returns the machine's physical memory, or 0 if unknown.
+func totalMemoryBytes() uint64 {
+	s, err := syscall.Sysctl("hw.memsize")
+	if err != nil {
+		return 0
+	}
+	// Sysctl treats the value as a string and drops its trailing NUL byte
+	b := []byte(s)
+	for len(b) < 8 {
+		b = append(b, 0)
+	}
+	return binary.LittleEndian.Uint64(b)
+}
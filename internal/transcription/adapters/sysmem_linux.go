@@ -0,0 +1,15 @@
+//go:build linux
+// +build linux
+
+package adapters
+
+import "syscall"
+
+// totalMemoryBytes returns the machine's physical memory, or 0 if unknown.
+func totalMemoryBytes() uint64 {
+	var info syscall.Sysinfo_t
+	if err := syscall.Sysinfo(&info); err != nil {
+		return 0
+	}
+	return uint64(info.Totalram) * uint64(info.Unit)
+}
@@ -0,0 +1,9 @@
+//go:build windows
+// +build windows
+
+package adapters
+
+// totalMemoryBytes is not implemented on Windows and reports 0 (unknown).
+func totalMemoryBytes() uint64 {
+	return 0
+}
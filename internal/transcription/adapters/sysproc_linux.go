@@ -0,0 +1,27 @@
+//go:build linux
+// +build linux
+
+package adapters
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// configureCmdSysProcAttr starts the command in its own process group on
+// Linux so the whole tree can be killed.
+func configureCmdSysProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessTree sends SIGKILL to the entire process group on Linux.
+func killProcessTree(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
+
+// processAlive reports whether a process with the given PID exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
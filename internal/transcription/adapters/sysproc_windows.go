@@ -0,0 +1,31 @@
+//go:build windows
+// +build windows
+
+package adapters
+
+import (
+	"os"
+	"os/exec"
+)
+
+// configureCmdSysProcAttr is a no-op on Windows to keep builds portable.
+func configureCmdSysProcAttr(cmd *exec.Cmd) {
+	// No special attributes set on Windows here
+}
+
+// killProcessTree kills only the process itself; Windows lacks a simple
+// process group SIGKILL equivalent.
+func killProcessTree(p *os.Process) error {
+	return p.Kill()
+}
+
+// processAlive reports whether a process with the given PID exists; opening
+// it fails once it has exited
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,593 @@
+# Python bridge run by the MLX adapter: transcribes one file with mlx_whisper
+# and writes the result as JSON. Installed into the MLX environment by
+# PrepareEnvironment.
+import argparse
+import dataclasses
+import json
+import mlx_whisper
+from mlx_whisper.decoding import DecodingOptions
+import math
+import os
+import random
+import threading
+import time
+
+from mlx_quantization import QUANTIZATION_TAGS, download_quantization
+
+TURN_WINDOW = 1.5     # seconds of audio compared on each side of a boundary
+TURN_THRESHOLD = 1.0  # minimum combined score to report a boundary
+SAMPLE_RATE = 16000
+
+SKIP_FILE = "skip_segment"
+SKIPPED_TEXT = "[skipped]"
+# Temperature reported for abandoned segments; real decodes are never negative
+SKIPPED_TEMPERATURE = -1.0
+
+# decoding is set while a segment is decoded and skip_requested asks the
+# decode loop to abandon it. skip_lock makes checking one and setting the
+# other atomic, so a request never outlives the decode it was meant for.
+decoding = threading.Event()
+skip_requested = threading.Event()
+skip_lock = threading.Lock()
+
+class SkipSegment(Exception):
+    pass
+
+def watch_skip_requests(control_dir):
+    # Flag a skip when the skip control file appears. Requests that arrive
+    # while nothing is being decoded are discarded.
+    path = os.path.join(control_dir, SKIP_FILE)
+    while True:
+        if os.path.exists(path):
+            try:
+                os.remove(path)
+            except OSError:
+                pass
+            with skip_lock:
+                if decoding.is_set():
+                    skip_requested.set()
+        time.sleep(0.2)
+
+def install_skip_hook():
+    from mlx_whisper.decoding import DecodingResult, Inference
+    from mlx_whisper.whisper import Whisper
+
+    original_decode = Whisper.decode
+    original_logits = Inference.logits
+
+    # The decode loop computes logits once per token, so checking here
+    # abandons the segment within a token of the request, from inside decode
+    def logits(self, tokens, audio_features):
+        if skip_requested.is_set():
+            raise SkipSegment()
+        return original_logits(self, tokens, audio_features)
+
+    def decode(self, mel, options=DecodingOptions()):
+        with skip_lock:
+            decoding.set()
+            skip_requested.clear()
+        try:
+            return original_decode(self, mel, options)
+        except SkipSegment:
+            print("INFO Skipping current segment on request", flush=True)
+            return DecodingResult(
+                audio_features=None,
+                language=options.language or "en",
+                tokens=[],
+                text="",
+                avg_logprob=0.0,
+                no_speech_prob=0.0,
+                temperature=SKIPPED_TEMPERATURE,
+                compression_ratio=0.0,
+            )
+        finally:
+            with skip_lock:
+                decoding.clear()
+                skip_requested.clear()
+
+    Inference.logits = logits
+    Whisper.decode = decode
+
+def is_bad_float(value):
+    return isinstance(value, float) and (math.isnan(value) or math.isinf(value))
+
+# NaN/Infinity values crash Go's JSON parser. By default non-finite values are
+# dropped from their object so the Go side sees the field as absent (nil)
+# rather than a misleading zero; --nan-handling=zero reports them as 0.0.
+def clean_obj(obj, nan_handling="omit"):
+    if isinstance(obj, float):
+        if is_bad_float(obj):
+            return 0.0 if nan_handling == "zero" else None
+        return obj
+    elif isinstance(obj, dict):
+        cleaned = {}
+        for k, v in obj.items():
+            if is_bad_float(v) and nan_handling == "omit":
+                continue
+            cleaned[k] = clean_obj(v, nan_handling)
+        return cleaned
+    elif isinstance(obj, list):
+        return [clean_obj(v, nan_handling) for v in obj]
+    return obj
+
+def estimate_pitch(clip):
+    # Median autocorrelation pitch over 40ms voiced frames (60-400 Hz)
+    import numpy as np
+    frame = int(0.04 * SAMPLE_RATE)
+    lo, hi = SAMPLE_RATE // 400, SAMPLE_RATE // 60
+    pitches = []
+    for i in range(0, len(clip) - frame, frame):
+        x = clip[i:i + frame] - clip[i:i + frame].mean()
+        if np.sqrt(np.mean(x ** 2)) < 0.01:
+            continue
+        corr = np.fft.irfft(np.abs(np.fft.rfft(x, 2 * frame)) ** 2)[:frame]
+        lag = lo + int(np.argmax(corr[lo:hi]))
+        if corr[0] > 0 and corr[lag] > 0.3 * corr[0]:
+            pitches.append(SAMPLE_RATE / lag)
+    return float(np.median(pitches)) if pitches else None
+
+def window_features(audio, start, end):
+    import numpy as np
+    clip = audio[max(0, int(start * SAMPLE_RATE)):max(0, int(end * SAMPLE_RATE))]
+    if len(clip) < SAMPLE_RATE // 10:
+        return None
+    rms = float(np.sqrt(np.mean(clip ** 2))) + 1e-10
+    return 20 * math.log10(rms), estimate_pitch(clip)
+
+def detect_turns(audio_path, segments):
+    # Score each segment boundary by how much loudness and pitch jump across
+    # it. This is a cheap stand-in for diarization: it finds likely changes of
+    # speaker but says nothing about who is speaking.
+    from mlx_whisper.audio import load_audio
+    audio = load_audio(audio_path)
+    turns = []
+    for i in range(1, len(segments)):
+        prev, nxt = segments[i - 1], segments[i]
+        before = window_features(audio, max(prev["start"], prev["end"] - TURN_WINDOW), prev["end"])
+        after = window_features(audio, nxt["start"], min(nxt["end"], nxt["start"] + TURN_WINDOW))
+        if before is None or after is None:
+            continue
+        score = min(abs(before[0] - after[0]) / 6.0, 1.0)
+        if before[1] and after[1]:
+            score += min(abs(before[1] - after[1]) / min(before[1], after[1]) / 0.25, 1.5)
+        if score >= TURN_THRESHOLD:
+            turns.append({
+                "time": (prev["end"] + nxt["start"]) / 2,
+                "score": round(score, 3),
+                "segment_index": i,
+            })
+    return turns
+
+def quantized_repo_exists(repo_id):
+    try:
+        from huggingface_hub import repo_exists, try_to_load_from_cache
+        # A cached variant exists; only ask the hub about ones never downloaded
+        if isinstance(try_to_load_from_cache(repo_id, "config.json"), str):
+            return True
+        return repo_exists(repo_id)
+    except Exception:
+        # Offline or an old huggingface_hub: assume it exists and let loading
+        # report the problem
+        return True
+
+def installed_version():
+    try:
+        from importlib.metadata import version
+        return version("mlx-whisper")
+    except Exception:
+        return ""
+
+def install_progress_hook(fd, job_id, lines):
+    # mlx_whisper reports progress through a tqdm bar (disabled unless
+    # verbose); count its updates and forward them as JSON Lines events on
+    # fd and/or as "PROGRESS <fraction>" lines on stdout
+    import datetime
+    import tqdm
+
+    out = os.fdopen(fd, "w", buffering=1) if fd is not None else None
+    base = tqdm.tqdm
+
+    class ProgressBar(base):
+        def __init__(self, *a, **kw):
+            super().__init__(*a, **kw)
+            self.done = 0
+
+        def update(self, n=1):
+            super().update(n)
+            self.done += n
+            if not self.total:
+                return
+            fraction = round(min(self.done / self.total, 1.0), 4)
+            if lines:
+                print(f"PROGRESS {fraction}", flush=True)
+            if out is None:
+                return
+            event = {
+                "time": datetime.datetime.now(datetime.timezone.utc).isoformat(),
+                "job_id": job_id,
+                "event": "progress",
+                "progress": fraction,
+            }
+            try:
+                out.write(json.dumps(event) + "\n")
+            except OSError:
+                pass
+
+    tqdm.tqdm = ProgressBar
+
+class SegmentStreamer:
+    # Prints segments as "SEGMENT <json>" lines on stdout and/or appends them
+    # as JSON lines to a partial output file, so they survive a crash. While
+    # live, they are picked up from mlx_whisper's transcribe loop as each
+    # window is decoded; emit() at the end handles whatever is still missing.
+    def __init__(self, nan_handling, print_segments, partial_path=None):
+        self.nan_handling = nan_handling
+        self.print_segments = print_segments
+        self.partial = open(partial_path, "w") if partial_path else None
+        self.sent = 0
+        self.live = False
+
+    def emit(self, segments):
+        for segment in segments[self.sent:]:
+            segment = dict(segment)
+            if segment.get("temperature") == SKIPPED_TEMPERATURE:
+                segment["text"] = SKIPPED_TEXT
+                segment["words"] = []
+            line = json.dumps(clean_obj(segment, self.nan_handling))
+            if self.print_segments:
+                print("SEGMENT " + line, flush=True)
+            if self.partial:
+                self.partial.write(line + "\n")
+        if self.partial:
+            self.partial.flush()
+        self.sent = max(self.sent, len(segments))
+
+    def install(self):
+        # mlx_whisper updates its tqdm bar right after adding a window's
+        # segments to the all_segments list of the transcribe loop, so look
+        # that list up in the calling frames
+        import sys
+        import tqdm
+
+        streamer = self
+        base = tqdm.tqdm
+
+        class SegmentBar(base):
+            def update(self, n=1):
+                result = super().update(n)
+                frame = sys._getframe(1)
+                for _ in range(4):
+                    if not streamer.live or frame is None:
+                        break
+                    segments = frame.f_locals.get("all_segments")
+                    if isinstance(segments, list):
+                        streamer.emit(segments)
+                        break
+                    frame = frame.f_back
+                return result
+
+        tqdm.tqdm = SegmentBar
+
+def model_details(model_path, fp16):
+    # Describe the checkpoint that was actually loaded, from its config.json.
+    # By now the weights are local, so the hub is not contacted.
+    details = {"dtype": "float16" if fp16 else "float32", "quantization": "none"}
+    try:
+        directory = model_path
+        if not os.path.exists(directory):
+            from huggingface_hub import snapshot_download
+            directory = snapshot_download(repo_id=model_path, allow_patterns=["config.json"], local_files_only=True)
+        with open(os.path.join(directory, "config.json")) as f:
+            config = json.load(f)
+    except Exception:
+        return details
+    quantization = config.get("quantization")
+    if isinstance(quantization, dict) and quantization.get("bits"):
+        details["quantization"] = f"{quantization['bits']}bit"
+        if quantization.get("group_size"):
+            details["quantization_group_size"] = str(quantization["group_size"])
+    for key, name in (("n_audio_layer", "encoder_layers"), ("n_text_layer", "decoder_layers"),
+                      ("n_mels", "mel_bins"), ("n_vocab", "vocab_size")):
+        if key in config:
+            details[name] = str(config[key])
+    return details
+
+def transcribe_ranges(run, ranges):
+    # Transcribe each extracted range (the model is loaded once and reused)
+    # and merge them, shifting times back onto the original timeline
+    segments, texts, language = [], [], None
+    for r in ranges:
+        part = run(audio=r["audio"])
+        language = language or part.get("language")
+        texts.append(part.get("text", "").strip())
+        for s in part.get("segments", []):
+            s["start"] += r["offset"]
+            s["end"] += r["offset"]
+            for w in s.get("words", []):
+                w["start"] += r["offset"]
+                w["end"] += r["offset"]
+            s["range_index"] = r["index"]
+            s["id"] = len(segments)
+            segments.append(s)
+    return {"text": " ".join(t for t in texts if t), "segments": segments, "language": language}
+
+def window_clips(duration, window, offset):
+    # Flattened start,end pairs covering the audio in fixed windows, with the
+    # first window cut short so later ones start at offset + k * window
+    edges = [0.0]
+    t = offset if offset > 0 else window
+    while t < duration:
+        edges.append(t)
+        t += window
+    edges.append(duration)
+    clips = []
+    for start, end in zip(edges, edges[1:]):
+        clips += [start, end]
+    return clips
+
+def segments_confidence(segments):
+    probs = [w["probability"] for s in segments for w in s.get("words", []) if "probability" in w]
+    if probs:
+        return sum(probs) / len(probs)
+    logprobs = [s["avg_logprob"] for s in segments if "avg_logprob" in s]
+    return math.exp(sum(logprobs) / len(logprobs)) if logprobs else 0.0
+
+def segments_text(segments):
+    return " ".join(" ".join(s["text"].lower().split()) for s in segments)
+
+def vote_overlaps(primary, shifted, boundaries, margin):
+    # Around each window boundary of the primary pass, compare the primary
+    # segments with the shifted pass (whose window spans the boundary) and
+    # keep whichever version is more confident
+    segments = list(primary)
+    conflicts = 0
+    for t in boundaries:
+        idx = [i for i, s in enumerate(segments) if s["end"] > t - margin and s["start"] < t + margin]
+        if not idx:
+            continue
+        first, last = idx[0], idx[-1]
+        start, end = segments[first]["start"], segments[last]["end"]
+        candidates = [s for s in shifted if start <= (s["start"] + s["end"]) / 2 <= end]
+        current = segments[first:last + 1]
+        if not candidates or segments_text(current) == segments_text(candidates):
+            continue
+        conflicts += 1
+        if segments_confidence(candidates) > segments_confidence(current):
+            segments[first:last + 1] = candidates
+    for i, s in enumerate(segments):
+        s["id"] = i
+    return segments, conflicts
+
+def get_normalizer(language):
+    # Whisper's own WER normalizers, from the standalone whisper-normalizer
+    # package; fall back to a basic lowercase/strip-punctuation version
+    try:
+        if language == "en":
+            from whisper_normalizer.english import EnglishTextNormalizer
+            return EnglishTextNormalizer()
+        from whisper_normalizer.basic import BasicTextNormalizer
+        return BasicTextNormalizer()
+    except ImportError:
+        import re
+        import unicodedata
+        def basic(text):
+            text = "".join(
+                " " if unicodedata.category(c)[0] in "PS" else c
+                for c in unicodedata.normalize("NFKC", text.lower())
+            )
+            return re.sub(r"\s+", " ", text).strip()
+        return basic
+
+def main():
+    parser = argparse.ArgumentParser()
+    parser.add_argument("--audio", required=True)
+    parser.add_argument("--model", required=True)
+    parser.add_argument("--output", required=True)
+    parser.add_argument("--nan-handling", choices=["omit", "zero"], default="omit")
+    parser.add_argument("--local-files-only", action="store_true")
+    parser.add_argument("--decoding-options")
+    parser.add_argument("--extra-options")
+    parser.add_argument("--task", choices=["transcribe", "translate"], default="transcribe")
+    parser.add_argument("--language")
+    parser.add_argument("--no-word-timestamps", action="store_true")
+    parser.add_argument("--best-of", type=int)
+    parser.add_argument("--temperature", help="temperature or comma-separated fallback schedule")
+    parser.add_argument("--seed", type=int)
+    parser.add_argument("--suppress-tokens", help="comma-separated token IDs")
+    parser.add_argument("--suppress-blank", choices=["true", "false"])
+    parser.add_argument("--condition-on-previous-text", choices=["true", "false"])
+    parser.add_argument("--no-speech-threshold", type=float, default=0.6)
+    parser.add_argument("--compression-ratio-threshold", type=float, default=2.4)
+    parser.add_argument("--initial-prompt-file")
+    parser.add_argument("--lightweight-turns", action="store_true")
+    parser.add_argument("--normalize", action="store_true")
+    parser.add_argument("--control-dir")
+    parser.add_argument("--voting-window", type=float)
+    parser.add_argument("--voting-overlap", type=float)
+    parser.add_argument("--only-quantization")
+    parser.add_argument("--progress-fd", type=int)
+    parser.add_argument("--progress-lines", action="store_true")
+    parser.add_argument("--stream-segments", action="store_true")
+    parser.add_argument("--partial-output")
+    parser.add_argument("--job-id", default="")
+    parser.add_argument("--ranges-manifest")
+    parser.add_argument("--batch-manifest")
+    parser.add_argument("--quantization", choices=["4bit", "8bit", "none"], default="none")
+    args = parser.parse_args()
+
+    initial_prompt = None
+    if args.initial_prompt_file:
+        with open(args.initial_prompt_file, encoding="utf-8") as f:
+            initial_prompt = f.read()
+
+    decode_options = {}
+    if args.decoding_options:
+        with open(args.decoding_options) as f:
+            decode_options = json.load(f)
+        allowed = {field.name for field in dataclasses.fields(DecodingOptions)}
+        unknown = sorted(set(decode_options) - allowed)
+        if unknown:
+            raise SystemExit(f"Unknown DecodingOptions fields: {', '.join(unknown)}")
+
+    # Raw keyword arguments for mlx_whisper.transcribe; anything the script
+    # sets itself takes precedence
+    extra_options = {}
+    if args.extra_options:
+        with open(args.extra_options) as f:
+            extra_options = json.load(f)
+        for key in ("word_timestamps", "no_speech_threshold", "compression_ratio_threshold", "initial_prompt"):
+            if extra_options.pop(key, None) is not None:
+                print(f"INFO Ignoring extra option {key}: it has its own parameter", flush=True)
+
+    # Explicit arguments take precedence over the raw decoding options
+    decode_options["task"] = args.task
+    if args.language:
+        decode_options["language"] = args.language
+    # Only used at temperatures above 0; mlx_whisper drops it otherwise
+    if args.best_of is not None:
+        decode_options["best_of"] = args.best_of
+    if args.temperature:
+        schedule = tuple(float(t) for t in args.temperature.split(","))
+        decode_options["temperature"] = schedule[0] if len(schedule) == 1 else schedule
+    if args.suppress_tokens is not None:
+        decode_options["suppress_tokens"] = [int(t) for t in args.suppress_tokens.split(",") if t.strip()]
+    if args.suppress_blank is not None:
+        decode_options["suppress_blank"] = args.suppress_blank == "true"
+    if args.condition_on_previous_text is not None:
+        decode_options["condition_on_previous_text"] = args.condition_on_previous_text == "true"
+    if args.seed is not None:
+        # Sampling only happens above temperature 0, so pinning it (and
+        # dropping the fallback schedule) removes decoding randomness; the
+        # seeds cover anything else that draws random numbers
+        import mlx.core as mx
+        import numpy as np
+        random.seed(args.seed)
+        np.random.seed(args.seed)
+        mx.random.seed(args.seed)
+        decode_options["temperature"] = 0.0
+
+    # mlx-community publishes each quantization as its own repository with a
+    # -4bit/-8bit suffix. Not every model has every variant, so fall back to
+    # the unquantized repository when the hub says the variant is missing.
+    if args.quantization in QUANTIZATION_TAGS and not os.path.exists(args.model) \
+            and not args.model.endswith(tuple("-" + tag for tag in QUANTIZATION_TAGS)):
+        quantized = f"{args.model}-{args.quantization}"
+        if args.local_files_only or quantized_repo_exists(quantized):
+            args.model = quantized
+        else:
+            print(f"INFO No {args.quantization} variant of {args.model}; using the unquantized model", flush=True)
+
+    print(f"INFO Loading model {args.model}...", flush=True)
+
+    model_path = args.model
+    if args.local_files_only and not os.path.exists(model_path):
+        # Resolve the snapshot directly so mlx_whisper never touches the hub
+        # (which would try to create lock files in a read-only cache)
+        from huggingface_hub import snapshot_download
+        model_path = snapshot_download(repo_id=args.model, local_files_only=True)
+    elif args.only_quantization and not os.path.exists(model_path):
+        model_path = download_quantization(args.model, args.only_quantization)
+
+    if args.progress_fd is not None or args.progress_lines:
+        install_progress_hook(args.progress_fd, args.job_id, args.progress_lines)
+    if args.progress_lines:
+        print("PROGRESS 0.0 started", flush=True)
+    streamer = None
+    if args.stream_segments or args.partial_output:
+        streamer = SegmentStreamer(args.nan_handling, args.stream_segments, args.partial_output)
+        streamer.install()
+
+    if args.control_dir:
+        install_skip_hook()
+        threading.Thread(target=watch_skip_requests, args=(args.control_dir,), daemon=True).start()
+
+    def run(audio=args.audio, **extra):
+        options = dict(extra_options)
+        options.update(decode_options)
+        options.update(extra)
+        return mlx_whisper.transcribe(
+            audio,
+            path_or_hf_repo=model_path,
+            word_timestamps=not args.no_word_timestamps,
+            no_speech_threshold=args.no_speech_threshold,
+            compression_ratio_threshold=args.compression_ratio_threshold,
+            initial_prompt=initial_prompt,
+            **options
+        )
+
+    def finish(result, audio, output):
+        for segment in result.get("segments", []):
+            if segment.get("temperature") == SKIPPED_TEMPERATURE:
+                segment["text"] = SKIPPED_TEXT
+                segment["words"] = []
+
+        if args.lightweight_turns:
+            result["turn_boundaries"] = detect_turns(audio, result.get("segments", []))
+
+        if args.normalize:
+            normalize = get_normalizer(result.get("language"))
+            result["normalized_text"] = normalize(result.get("text", ""))
+            for segment in result.get("segments", []):
+                segment["normalized_text"] = normalize(segment.get("text", ""))
+
+        result["mlx_whisper_version"] = installed_version()
+        result["model"] = args.model
+        result["model_details"] = model_details(model_path, decode_options.get("fp16", True))
+
+        # Clean NaNs/Infs which cause JSON errors in Go/other parsers
+        result = clean_obj(result, args.nan_handling)
+
+        if streamer:
+            streamer.emit(result.get("segments", []))
+
+        # Save to JSON
+        with open(output, "w") as f:
+            json.dump(result, f, indent=2)
+
+    if args.batch_manifest:
+        # mlx_whisper keeps the loaded model between calls, so every file
+        # after the first skips loading it. A failed file gets an error
+        # object instead of a result and the batch goes on.
+        with open(args.batch_manifest) as f:
+            batch = json.load(f)
+        for item in batch:
+            try:
+                finish(run(audio=item["audio"]), item["audio"], item["output"])
+            except Exception as e:
+                with open(item["output"], "w") as f:
+                    json.dump({"error": f"{type(e).__name__}: {e}"}, f)
+        if args.progress_lines:
+            print("PROGRESS 1.0 finished", flush=True)
+        return
+
+    # Transcribe
+    if args.voting_window:
+        from mlx_whisper.audio import load_audio
+        duration = len(load_audio(args.audio)) / SAMPLE_RATE
+        window, shift = args.voting_window, args.voting_overlap
+        result = run(clip_timestamps=window_clips(duration, window, 0))
+        shifted = run(clip_timestamps=window_clips(duration, window, shift))
+        boundaries = [k * window for k in range(1, int(duration // window) + 1) if k * window < duration]
+        margin = min(shift, window - shift) / 2
+        result["segments"], result["overlap_conflicts"] = vote_overlaps(
+            result.get("segments", []), shifted.get("segments", []), boundaries, margin)
+        result["text"] = "".join(s["text"] for s in result["segments"])
+    elif args.ranges_manifest:
+        with open(args.ranges_manifest) as f:
+            ranges = json.load(f)
+        result = transcribe_ranges(run, ranges)
+    else:
+        # Only a plain run has final segment times while decoding; the other
+        # modes are streamed once they are done
+        if streamer:
+            streamer.live = True
+        result = run()
+        if streamer:
+            streamer.live = False
+    finish(result, args.audio, args.output)
+
+    if args.progress_lines:
+        print("PROGRESS 1.0 finished", flush=True)
+
+if __name__ == "__main__":
+    main()
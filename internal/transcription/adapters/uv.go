@@ -0,0 +1,75 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// MinUVVersion is the oldest uv release with project support (uv init --name)
+const MinUVVersion = "0.4.0"
+
+// CheckUV verifies that uv is on PATH and recent enough to manage adapter
+// environments, returning an error that says how to fix it if not
+func CheckUV(ctx context.Context) error {
+	return checkUV(ctx, execRunner{})
+}
+
+// checkUV is CheckUV running uv through r
+func checkUV(ctx context.Context, r CommandRunner) error {
+	cmd := exec.CommandContext(ctx, "uv", "--version")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := r.Run(ctx, cmd); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return fmt.Errorf("uv not found on PATH; install from https://astral.sh/uv (PATH=%s)", os.Getenv("PATH"))
+		}
+		return fmt.Errorf("failed to run %s --version: %w", cmd.Path, err)
+	}
+	uvPath := cmd.Path
+	version, err := parseUVVersion(stdout.String())
+	if err != nil {
+		return err
+	}
+	if compareVersions(version, MinUVVersion) < 0 {
+		return fmt.Errorf("uv %s at %s is too old: version %s or newer is required; upgrade with `uv self update` or from https://astral.sh/uv", version, uvPath, MinUVVersion)
+	}
+	return nil
+}
+
+// parseUVVersion extracts the version from `uv --version` output, e.g.
+// "uv 0.4.18 (7b55e9790 2024-10-01)"
+func parseUVVersion(output string) (string, error) {
+	fields := strings.Fields(output)
+	if len(fields) < 2 || fields[0] != "uv" {
+		return "", fmt.Errorf("unexpected uv --version output %q", strings.TrimSpace(output))
+	}
+	return fields[1], nil
+}
+
+// compareVersions compares dotted numeric versions, treating missing or
+// non-numeric parts as 0
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
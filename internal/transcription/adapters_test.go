@@ -2,6 +2,7 @@ package transcription
 
 import (
 	"context"
+	"runtime"
 	"testing"
 	"time"
 
@@ -179,6 +180,39 @@ func TestModelRegistry(t *testing.T) {
 	t.Logf("Registered diarization models: %v", diarizationModels)
 }
 
+func TestAdapterAvailability(t *testing.T) {
+	registry.RegisterTranscriptionAdapter("mock-transcription", new(MockTranscriptionAdapter))
+	reg := registry.GetRegistry()
+
+	// MLX needs Apple Silicon; every other platform gets a reason instead
+	wantMLX := runtime.GOOS == "darwin" && runtime.GOARCH == "arm64"
+	available, reason := reg.IsAvailable("mlx_whisper")
+	if available != wantMLX {
+		t.Errorf("mlx_whisper available = %v on %s/%s, want %v", available, runtime.GOOS, runtime.GOARCH, wantMLX)
+	}
+	if !available && reason == "" {
+		t.Error("Unavailable adapter should explain why")
+	}
+	if !wantMLX {
+		if err := adapters.NewMLXAdapter(t.TempDir()).PrepareEnvironment(context.Background()); err == nil || err.Error() != reason {
+			t.Errorf("PrepareEnvironment should fail with %q, got %v", reason, err)
+		}
+	}
+
+	// Adapters without an availability check are always available
+	if ok, _ := reg.IsAvailable("mock-transcription"); !ok {
+		t.Error("Adapter without an availability check should be available")
+	}
+	if ok, _ := reg.IsAvailable("no-such-model"); ok {
+		t.Error("Unregistered model should not be available")
+	}
+	for _, id := range reg.GetAvailableTranscriptionModels() {
+		if id == "mlx_whisper" && !wantMLX {
+			t.Errorf("mlx_whisper listed as available on %s/%s", runtime.GOOS, runtime.GOARCH)
+		}
+	}
+}
+
 func TestWhisperXAdapter(t *testing.T) {
 	reg := registry.GetRegistry()
 	registry.RegisterTranscriptionAdapter("whisperx", adapters.NewWhisperXAdapter("/tmp/whisperx"))
@@ -486,6 +520,29 @@ func TestParameterConversion(t *testing.T) {
 	}
 }
 
+func TestMLXParameterConversion(t *testing.T) {
+	service := NewUnifiedTranscriptionService(new(MockJobRepository))
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+
+	tests := []struct {
+		model string
+		want  interface{}
+	}{
+		{"small", nil},
+		{"large-v3", "mlx-community/whisper-large-v3-mlx"},
+		{"mlx-community/whisper-base-mlx", "mlx-community/whisper-base-mlx"},
+	}
+	for _, tt := range tests {
+		params := service.convertToMLXParams(models.WhisperXParams{Model: tt.model, Task: "transcribe"})
+		if params["model"] != tt.want {
+			t.Errorf("Model %q: expected MLX model %v, got %v", tt.model, tt.want, params["model"])
+		}
+		if err := adapter.ValidateParameters(params); err != nil {
+			t.Errorf("Model %q: converted parameters failed validation: %v", tt.model, err)
+		}
+	}
+}
+
 // Helper functions
 func stringPtr(s string) *string {
 	return &s
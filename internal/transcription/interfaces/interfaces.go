@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"scriberr/internal/models"
@@ -25,7 +26,7 @@ type ModelCapabilities struct {
 // ParameterSchema defines a parameter that a model accepts
 type ParameterSchema struct {
 	Name        string      `json:"name"`
-	Type        string      `json:"type"` // "int", "float", "string", "bool", "[]string"
+	Type        string      `json:"type"` // "int", "float", "string", "bool", "[]string", "object", "[][2]float64", "[]float64"
 	Required    bool        `json:"required"`
 	Default     interface{} `json:"default"`
 	Min         *float64    `json:"min,omitempty"`
@@ -53,6 +54,11 @@ type AudioInput struct {
 	Size         int64             `json:"size"`
 	Metadata     map[string]string `json:"metadata"`
 	TempFilePath string            `json:"temp_file_path,omitempty"` // For converted files
+
+	// Reader supplies the audio as a stream when there is no FilePath; Format
+	// must then be set. Adapters spool it to a temp file before use. FilePath
+	// takes precedence if both are set.
+	Reader io.Reader `json:"-"`
 }
 
 // TranscriptSegment represents a segment of transcribed audio
@@ -62,6 +68,30 @@ type TranscriptSegment struct {
 	Text     string  `json:"text"`
 	Speaker  *string `json:"speaker,omitempty"`
 	Language *string `json:"language,omitempty"`
+
+	// Decoder confidence values. Nil means the model did not report a usable
+	// value, which is distinct from a reported zero.
+	AvgLogProb       *float64 `json:"avg_logprob,omitempty"`
+	NoSpeechProb     *float64 `json:"no_speech_prob,omitempty"`
+	CompressionRatio *float64 `json:"compression_ratio,omitempty"` // gzip ratio of the text; high values suggest repetition
+
+	// Labels holds tags attached by a SegmentAnalyzer (e.g. "sentiment")
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// NormalizedText is Text passed through Whisper's WER normalizer, if requested
+	NormalizedText string `json:"normalized_text,omitempty"`
+
+	// Absolute wall-clock times, set when the recording start time is known
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+
+	// RangeIndex is the index of the requested time range this segment was
+	// transcribed from, when only selected ranges were transcribed
+	RangeIndex *int `json:"range_index,omitempty"`
+
+	// Words holds the word timings within this segment. Nil when the model
+	// produced no word-level timestamps.
+	Words []WordTiming `json:"words,omitempty"`
 }
 
 // TranscriptWord represents word-level timing information
@@ -71,6 +101,26 @@ type TranscriptWord struct {
 	Word    string  `json:"word"`
 	Score   float64 `json:"score"`
 	Speaker *string `json:"speaker,omitempty"`
+
+	// Probability is the model's confidence in the word. Nil means the model
+	// did not report one.
+	Probability *float64 `json:"probability,omitempty"`
+
+	// Chars holds approximate character timings, if requested
+	Chars []CharTiming `json:"chars,omitempty"`
+}
+
+// WordTiming is the per-segment word timing; it is the same shape as the
+// flattened TranscriptResult.WordSegments entries
+type WordTiming = TranscriptWord
+
+// CharTiming represents the timing of a single character within a word.
+// These timings are interpolated from the word timing in proportion to the
+// character count; they are not produced by the model.
+type CharTiming struct {
+	Char  string  `json:"char"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
 }
 
 // TranscriptResult represents the output of transcription
@@ -83,6 +133,43 @@ type TranscriptResult struct {
 	ProcessingTime time.Duration    `json:"processing_time"`
 	ModelUsed    string             `json:"model_used"`
 	Metadata     map[string]string  `json:"metadata"`
+	JobID        string             `json:"job_id,omitempty"`
+
+	// ModelDetails describes the checkpoint ModelUsed refers to as loaded,
+	// e.g. dtype, quantization and layer counts, if the adapter reports it
+	ModelDetails map[string]string `json:"model_details,omitempty"`
+
+	// NormalizedText is Text passed through Whisper's WER normalizer, if requested
+	NormalizedText string `json:"normalized_text,omitempty"`
+
+	// TurnBoundaries marks likely speaker changes found by lightweight
+	// heuristics; speakers are not identified
+	TurnBoundaries []TurnBoundary `json:"turn_boundaries,omitempty"`
+
+	// Metrics reports throughput for the run, if the adapter measures it
+	Metrics *TranscriptMetrics `json:"metrics,omitempty"`
+
+	// Partial is set when the run failed and the result only holds the
+	// segments decoded before the failure
+	Partial bool `json:"partial,omitempty"`
+}
+
+// TranscriptMetrics describes how long a transcription took relative to the
+// audio it processed
+type TranscriptMetrics struct {
+	ProcessingDuration time.Duration `json:"processing_duration"`
+	AudioDuration      time.Duration `json:"audio_duration"` // 0 if unknown
+	// RealTimeFactor is processing time divided by audio time (below 1 is
+	// faster than real time); 0 if the audio duration is unknown
+	RealTimeFactor float64 `json:"real_time_factor"`
+}
+
+// TurnBoundary marks a likely change of speaker between two segments
+type TurnBoundary struct {
+	Time  float64 `json:"time"`
+	Score float64 `json:"score"` // Heuristic strength; higher is more likely a real change
+	// SegmentIndex is the index of the first segment after the boundary
+	SegmentIndex int `json:"segment_index"`
 }
 
 // DiarizationSegment represents speaker diarization information
@@ -110,6 +197,51 @@ type ProcessingContext struct {
 	OutputDirectory string            `json:"output_directory"`
 	TempDirectory   string            `json:"temp_directory"`
 	Metadata        map[string]string `json:"metadata"`
+
+	// OutputNameTemplate derives output file names from the input file, e.g.
+	// "{basename}.{ext}". Empty keeps each adapter's fixed default names.
+	OutputNameTemplate string `json:"output_name_template,omitempty"`
+
+	// OutputSink, if set, receives output files (results, logs) instead of
+	// OutputDirectory
+	OutputSink OutputSink `json:"-"`
+
+	// Progress events are written as JSON Lines to an inherited file
+	// descriptor (ProgressFD, 0 means unset) or to a path such as a named pipe
+	// (ProgressPipe), separately from the log
+	ProgressFD   int    `json:"progress_fd,omitempty"`
+	ProgressPipe string `json:"progress_pipe,omitempty"`
+
+	// ProgressFn, if set, is called with the fraction done (0.0 to 1.0) as
+	// the adapter reports progress. It may be called from another goroutine.
+	ProgressFn func(fraction float64, message string) `json:"-"`
+
+	// DryRun makes adapters that run a subprocess return the command they
+	// would execute (in the result metadata) instead of running it
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// KeepTemp keeps each run's temp directory (script output, logs) instead
+	// of removing it; KeepTempOnError keeps it only for runs that fail. The
+	// kept path is reported in the result metadata ("temp_dir") or the error.
+	KeepTemp        bool `json:"keep_temp,omitempty"`
+	KeepTempOnError bool `json:"keep_temp_on_error,omitempty"`
+
+	// SkipAudioProbe skips the ffprobe check that the input has an audio
+	// stream and a non-zero duration, for callers that validated it already
+	SkipAudioProbe bool `json:"skip_audio_probe,omitempty"`
+
+	// Env sets extra environment variables on the adapter's subprocesses,
+	// e.g. OMP_NUM_THREADS or proxy settings. They override both the
+	// inherited environment and variables the adapter sets itself (such as
+	// HF_HOME); nil or empty inherits the current environment unchanged.
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// OutputSink stores named output files somewhere other than the local
+// filesystem, e.g. an object store. Names are plain file names without
+// directories.
+type OutputSink interface {
+	Put(name string, r io.Reader) error
 }
 
 // ModelAdapter is the base interface that all model adapters must implement
@@ -136,6 +268,30 @@ type ModelAdapter interface {
 	GetEstimatedProcessingTime(input AudioInput) time.Duration
 }
 
+// AvailabilityChecker is implemented by adapters that can only run on some
+// platforms. Adapters that do not implement it are always available.
+type AvailabilityChecker interface {
+	// Available reports whether the adapter can run on this machine and, if
+	// not, a reason suitable for showing to users
+	Available() (bool, string)
+}
+
+// HealthChecker is implemented by adapters that can verify their environment
+// is usable without processing any audio
+type HealthChecker interface {
+	// HealthCheck returns nil if the adapter can run jobs
+	HealthCheck(ctx context.Context) error
+}
+
+// MemoryEstimator is implemented by adapters whose memory use depends on the
+// parameters, so that schedulers can place jobs better than with the static
+// ModelCapabilities.MemoryRequirement
+type MemoryEstimator interface {
+	// EstimateMemory returns an upper bound in MB on the memory a run with
+	// params needs
+	EstimateMemory(params map[string]interface{}) int
+}
+
 // TranscriptionAdapter handles audio transcription
 type TranscriptionAdapter interface {
 	ModelAdapter
@@ -234,6 +390,31 @@ type Postprocessor interface {
 	AppliesTo(capabilities ModelCapabilities, params map[string]interface{}) bool
 }
 
+// Translator translates transcript text into another language. Adapters use it
+// to reach target languages the model cannot produce natively (Whisper can only
+// translate into English).
+type Translator interface {
+	// Translate converts text from sourceLang to targetLang (ISO-639-1 codes)
+	Translate(ctx context.Context, text, sourceLang, targetLang string) (string, error)
+}
+
+// SegmentAnalysisInput is what a SegmentAnalyzer receives for one segment
+type SegmentAnalysisInput struct {
+	Text     string  `json:"text"`
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Language string  `json:"language"`
+	// AudioPath is the full source audio; Start/End locate the segment in it
+	AudioPath string `json:"audio_path"`
+}
+
+// SegmentAnalyzer derives labels such as sentiment or emotion for a transcript
+// segment. Adapters run it after transcription and attach the returned labels
+// to the segment.
+type SegmentAnalyzer interface {
+	AnalyzeSegment(ctx context.Context, input SegmentAnalysisInput) (map[string]string, error)
+}
+
 // Legacy type aliases for backward compatibility
 type Segment = TranscriptSegment
 type Word = TranscriptWord
\ No newline at end of file
@@ -0,0 +1,62 @@
+package interfaces
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Placeholders supported in ProcessingContext.OutputNameTemplate
+const (
+	OutputNameBasename = "{basename}" // input file name without extension
+	OutputNameExt      = "{ext}"      // extension of the output kind, e.g. "json" or "log"
+	OutputNameJobID    = "{job_id}"   // ProcessingContext.JobID
+)
+
+// ValidateOutputNameTemplate checks that a template yields distinct names for
+// every output kind and cannot escape the output directory.
+func ValidateOutputNameTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if !strings.Contains(template, OutputNameExt) {
+		return fmt.Errorf("output name template %q must contain %s so outputs do not collide", template, OutputNameExt)
+	}
+
+	rest := template
+	for _, placeholder := range []string{OutputNameBasename, OutputNameExt, OutputNameJobID} {
+		rest = strings.ReplaceAll(rest, placeholder, "")
+	}
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("output name template %q contains an unknown placeholder", template)
+	}
+	if strings.ContainsAny(rest, `/\`+"\x00") {
+		return fmt.Errorf("output name template %q must not contain path separators", template)
+	}
+	return nil
+}
+
+// OutputFileName returns the file name for an output of the given extension.
+// When no template is set, defaultName is returned unchanged.
+func (p ProcessingContext) OutputFileName(inputPath, ext, defaultName string) (string, error) {
+	if p.OutputNameTemplate == "" {
+		return defaultName, nil
+	}
+	if err := ValidateOutputNameTemplate(p.OutputNameTemplate); err != nil {
+		return "", err
+	}
+
+	base := filepath.Base(inputPath)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+
+	name := strings.NewReplacer(
+		OutputNameBasename, base,
+		OutputNameExt, ext,
+		OutputNameJobID, p.JobID,
+	).Replace(p.OutputNameTemplate)
+
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`+"\x00") {
+		return "", fmt.Errorf("output name template %q produced an unsafe file name %q", p.OutputNameTemplate, name)
+	}
+	return name, nil
+}
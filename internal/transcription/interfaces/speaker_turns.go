@@ -0,0 +1,151 @@
+package interfaces
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// SpeakerTurn is a span of audio attributed to one speaker, e.g. by a
+// diarization model run separately from transcription
+type SpeakerTurn struct {
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+	Speaker string  `json:"speaker"`
+}
+
+// ApplySpeakerTurns sets the speaker of each segment to the turn it overlaps
+// most. A segment with word timings that overlaps several turns is split
+// where the speaker changes, each word going to the turn it overlaps most;
+// the pieces take their text from their words. A segment without word
+// timings cannot be split and gets the turn with the largest overlap.
+// Segments overlapping no turn are left unchanged. WordSegments is rebuilt
+// and TurnBoundaries are moved to the new segment indices.
+func (r *TranscriptResult) ApplySpeakerTurns(turns []SpeakerTurn) {
+	turns = append([]SpeakerTurn(nil), turns...)
+	sort.SliceStable(turns, func(i, j int) bool { return turns[i].Start < turns[j].Start })
+
+	segments := make([]TranscriptSegment, 0, len(r.Segments))
+	// firstPiece maps an original segment index to its first new index
+	firstPiece := make([]int, len(r.Segments)+1)
+	for i, seg := range r.Segments {
+		firstPiece[i] = len(segments)
+		segments = append(segments, splitBySpeaker(seg, turns)...)
+	}
+	firstPiece[len(r.Segments)] = len(segments)
+
+	for i := range r.TurnBoundaries {
+		if idx := r.TurnBoundaries[i].SegmentIndex; idx >= 0 && idx < len(firstPiece) {
+			r.TurnBoundaries[i].SegmentIndex = firstPiece[idx]
+		}
+	}
+	r.Segments = segments
+
+	if r.WordSegments != nil {
+		r.WordSegments = nil
+		for _, seg := range r.Segments {
+			r.WordSegments = append(r.WordSegments, seg.Words...)
+		}
+	}
+}
+
+// splitBySpeaker returns seg with speakers from turns applied, split into
+// one piece per run of words from the same speaker
+func splitBySpeaker(seg TranscriptSegment, turns []SpeakerTurn) []TranscriptSegment {
+	speaker, ok := bestTurn(seg.Start, seg.End, turns)
+	if !ok {
+		return []TranscriptSegment{seg}
+	}
+	if len(seg.Words) == 0 {
+		seg.Speaker = &speaker
+		return []TranscriptSegment{seg}
+	}
+
+	// Words outside every turn stay with the speaker before them (or the
+	// segment's speaker at the start)
+	words := make([]TranscriptWord, len(seg.Words))
+	current := speaker
+	for i, w := range seg.Words {
+		if s, ok := bestTurn(w.Start, w.End, turns); ok {
+			current = s
+		}
+		s := current
+		w.Speaker = &s
+		words[i] = w
+	}
+
+	// Whisper omits spaces between words in languages written without them
+	sep := " "
+	if !strings.Contains(strings.TrimSpace(seg.Text), " ") {
+		sep = ""
+	}
+
+	var pieces []TranscriptSegment
+	for start := 0; start < len(words); {
+		end := start + 1
+		for end < len(words) && *words[end].Speaker == *words[start].Speaker {
+			end++
+		}
+		pieces = append(pieces, speakerPiece(seg, words[start:end], sep))
+		start = end
+	}
+	if len(pieces) == 1 {
+		seg.Speaker = pieces[0].Speaker
+		seg.Words = words
+		return []TranscriptSegment{seg}
+	}
+	pieces[0].Start = seg.Start
+	pieces[len(pieces)-1].End = seg.End
+	for i := range pieces {
+		shiftWallClock(&pieces[i], seg)
+	}
+	return pieces
+}
+
+// speakerPiece builds the part of seg spoken by one speaker from its words.
+// Decoder statistics and labels are copied from seg; the normalized text
+// cannot be split and is left empty.
+func speakerPiece(seg TranscriptSegment, words []TranscriptWord, sep string) TranscriptSegment {
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.Word
+	}
+	piece := seg
+	piece.Start = words[0].Start
+	piece.End = words[len(words)-1].End
+	piece.Text = strings.Join(texts, sep)
+	piece.NormalizedText = ""
+	piece.Speaker = words[0].Speaker
+	piece.Words = words
+	return piece
+}
+
+// shiftWallClock moves the wall-clock times of a piece of orig by the same
+// amounts as its start and end
+func shiftWallClock(piece *TranscriptSegment, orig TranscriptSegment) {
+	if orig.StartTime != nil {
+		t := orig.StartTime.Add(time.Duration((piece.Start - orig.Start) * float64(time.Second)))
+		piece.StartTime = &t
+	}
+	if orig.StartTime != nil && orig.EndTime != nil {
+		t := orig.StartTime.Add(time.Duration((piece.End - orig.Start) * float64(time.Second)))
+		piece.EndTime = &t
+	}
+}
+
+// bestTurn returns the speaker of the turn overlapping [start, end] the
+// most; ties go to the earlier turn. Zero-length spans match the turn that
+// contains them.
+func bestTurn(start, end float64, turns []SpeakerTurn) (string, bool) {
+	best, bestOverlap, found := "", -1.0, false
+	for _, t := range turns {
+		overlap := min(end, t.End) - max(start, t.Start)
+		if overlap < 0 || (overlap == 0 && end > start) {
+			continue
+		}
+		if overlap > bestOverlap {
+			best, bestOverlap, found = t.Speaker, overlap, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,69 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TranscriptChunk is one message of a streamed transcript. Chunks are numbered
+// from 0 and the last one has Final set, so they can be forwarded one-to-one
+// as server-streaming responses.
+type TranscriptChunk struct {
+	Sequence int                 `json:"sequence"`
+	Segments []TranscriptSegment `json:"segments"`
+	Final    bool                `json:"final"`
+
+	// Language and JobID are only set on the final chunk
+	Language string `json:"language,omitempty"`
+	JobID    string `json:"job_id,omitempty"`
+}
+
+// ChunkOptions limits the size of each TranscriptChunk. Zero means no limit.
+type ChunkOptions struct {
+	MaxSegments int `json:"max_segments"`
+	// MaxBytes bounds the JSON-encoded size of the segments in a chunk
+	MaxBytes int `json:"max_bytes"`
+}
+
+// ChunkResult splits a result's segments into chunks within opts. A segment
+// larger than MaxBytes on its own is sent alone in its chunk. A result without
+// segments yields a single, empty final chunk.
+func ChunkResult(result *TranscriptResult, opts ChunkOptions) ([]TranscriptChunk, error) {
+	if opts.MaxSegments < 0 || opts.MaxBytes < 0 {
+		return nil, fmt.Errorf("chunk limits must not be negative")
+	}
+
+	var chunks []TranscriptChunk
+	var current []TranscriptSegment
+	currentBytes := 0
+	flush := func() {
+		chunks = append(chunks, TranscriptChunk{Sequence: len(chunks), Segments: current})
+		current = nil
+		currentBytes = 0
+	}
+
+	for _, seg := range result.Segments {
+		data, err := json.Marshal(seg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode segment: %w", err)
+		}
+		size := len(data)
+
+		full := opts.MaxSegments > 0 && len(current) >= opts.MaxSegments
+		tooBig := opts.MaxBytes > 0 && currentBytes+size > opts.MaxBytes
+		if len(current) > 0 && (full || tooBig) {
+			flush()
+		}
+		current = append(current, seg)
+		currentBytes += size
+	}
+	if len(current) > 0 || len(chunks) == 0 {
+		flush()
+	}
+
+	last := &chunks[len(chunks)-1]
+	last.Final = true
+	last.Language = result.Language
+	last.JobID = result.JobID
+	return chunks, nil
+}
@@ -0,0 +1,367 @@
+package interfaces
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SubtitleOptions controls ToSRTWithOptions and ToVTTWithOptions. The zero
+// value renders one cue per segment with its text unchanged.
+type SubtitleOptions struct {
+	// MaxLineWidth wraps cue text at word boundaries into lines of at most
+	// this many characters; 0 disables wrapping. Words longer than the width
+	// get a line of their own.
+	MaxLineWidth int
+	// MaxLineCount is the most lines a cue may have when wrapping (default
+	// 2). Longer segments are split into several cues, timed by their word
+	// timings or, without those, in proportion to their length.
+	MaxLineCount int
+	// HighlightWords renders karaoke-style captions from the word timings:
+	// in SRT one cue per word with the spoken word in a <font> tag, in VTT
+	// one cue per word group with a timing tag before each word. Segments
+	// without word timings are rendered as plain cues.
+	HighlightWords bool
+}
+
+// highlightColor is the <font> color of the spoken word in SRT captions
+const highlightColor = "#FFFF00"
+
+// ToSRT renders the segments as SubRip subtitles with HH:MM:SS,mmm timestamps
+func (r *TranscriptResult) ToSRT() string {
+	return r.ToSRTWithOptions(SubtitleOptions{})
+}
+
+// ToVTT renders the segments as WebVTT subtitles with HH:MM:SS.mmm timestamps
+func (r *TranscriptResult) ToVTT() string {
+	return r.ToVTTWithOptions(SubtitleOptions{})
+}
+
+// ToSRTWithOptions is ToSRT with line wrapping and word highlighting
+func (r *TranscriptResult) ToSRTWithOptions(opts SubtitleOptions) string {
+	return r.renderCues(",", opts)
+}
+
+// ToVTTWithOptions is ToVTT with line wrapping and word highlighting
+func (r *TranscriptResult) ToVTTWithOptions(opts SubtitleOptions) string {
+	return "WEBVTT\n\n" + r.renderCues(".", opts)
+}
+
+// cueToken is one word of a cue; start and end are only set when the word
+// came from word timings
+type cueToken struct {
+	text       string
+	start, end float64
+}
+
+// renderCues writes numbered cues separated by blank lines, one per segment
+// or, when opts call for it, per part of a segment. Empty segments are
+// skipped and cues whose end is not after their start are stretched to last
+// 1ms so players accept them.
+func (r *TranscriptResult) renderCues(msSep string, opts SubtitleOptions) string {
+	var sb strings.Builder
+	n := 0
+	writeCue := func(start, end float64, text string) {
+		startMs := secondsToMillis(start)
+		endMs := secondsToMillis(end)
+		if endMs <= startMs {
+			endMs = startMs + 1
+		}
+		n++
+		fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n\n", n, formatCueTime(startMs, msSep), formatCueTime(endMs, msSep), text)
+	}
+
+	for _, seg := range r.Segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		tokens, timed := segmentTokens(seg)
+		highlight := opts.HighlightWords && timed
+		if opts.MaxLineWidth <= 0 && !highlight {
+			writeCue(seg.Start, seg.End, text)
+			continue
+		}
+
+		groups := groupCueTokens(tokens, opts)
+		for g, group := range groups {
+			start, end := groupTimes(seg, groups, g, timed)
+			lines := wrapCueTokens(group, opts.MaxLineWidth)
+			switch {
+			case !highlight:
+				writeCue(start, end, renderCueLines(group, lines, func(i int, t cueToken) string { return t.text }))
+			case msSep == ".":
+				// WebVTT karaoke: each word after the first is preceded by
+				// the time it is spoken
+				writeCue(start, end, renderCueLines(group, lines, func(i int, t cueToken) string {
+					if i == 0 {
+						return t.text
+					}
+					return "<" + formatCueTime(secondsToMillis(t.start), ".") + ">" + t.text
+				}))
+			default:
+				for w := range group {
+					wordStart, wordEnd := group[w].start, end
+					if w == 0 {
+						wordStart = start
+					}
+					if w+1 < len(group) {
+						wordEnd = group[w+1].start
+					}
+					writeCue(wordStart, wordEnd, renderCueLines(group, lines, func(i int, t cueToken) string {
+						if i == w {
+							return `<font color="` + highlightColor + `">` + t.text + "</font>"
+						}
+						return t.text
+					}))
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+// segmentTokens returns the words of seg, from its word timings if it has
+// any (reporting true) and from its text otherwise
+func segmentTokens(seg TranscriptSegment) ([]cueToken, bool) {
+	var tokens []cueToken
+	for _, w := range seg.Words {
+		if text := strings.TrimSpace(w.Word); text != "" {
+			tokens = append(tokens, cueToken{text: text, start: w.Start, end: w.End})
+		}
+	}
+	if len(tokens) > 0 {
+		return tokens, true
+	}
+	for _, word := range strings.Fields(seg.Text) {
+		tokens = append(tokens, cueToken{text: word})
+	}
+	return tokens, false
+}
+
+// groupCueTokens splits tokens into the groups shown together in one cue,
+// each filling at most MaxLineCount wrapped lines
+func groupCueTokens(tokens []cueToken, opts SubtitleOptions) [][]cueToken {
+	if opts.MaxLineWidth <= 0 {
+		return [][]cueToken{tokens}
+	}
+	maxLines := opts.MaxLineCount
+	if maxLines <= 0 {
+		maxLines = 2
+	}
+
+	var groups [][]cueToken
+	start := 0
+	for i := 1; i <= len(tokens); i++ {
+		if i < len(tokens) && len(wrapCueTokens(tokens[start:i+1], opts.MaxLineWidth)) <= maxLines {
+			continue
+		}
+		groups = append(groups, tokens[start:i])
+		start = i
+	}
+	return groups
+}
+
+// groupTimes returns when group g of seg is shown. Timed groups run from
+// their first word to their last; untimed ones share the segment in
+// proportion to their length. The first and last group are extended to the
+// segment bounds.
+func groupTimes(seg TranscriptSegment, groups [][]cueToken, g int, timed bool) (float64, float64) {
+	var start, end float64
+	if timed {
+		start, end = groups[g][0].start, groups[g][len(groups[g])-1].end
+	} else {
+		total, before := 0, 0
+		for i, group := range groups {
+			for _, t := range group {
+				if i < g {
+					before += len([]rune(t.text))
+				}
+				total += len([]rune(t.text))
+			}
+		}
+		length := 0
+		for _, t := range groups[g] {
+			length += len([]rune(t.text))
+		}
+		duration := seg.End - seg.Start
+		start = seg.Start + duration*float64(before)/float64(total)
+		end = seg.Start + duration*float64(before+length)/float64(total)
+	}
+	if g == 0 {
+		start = seg.Start
+	}
+	if g == len(groups)-1 {
+		end = seg.End
+	}
+	return start, end
+}
+
+// wrapCueTokens breaks tokens into lines of at most width characters as
+// wrapWords does, keeping each token's index in the group
+func wrapCueTokens(tokens []cueToken, width int) [][]int {
+	var lines [][]int
+	lineLen := 0
+	for i, t := range tokens {
+		n := len([]rune(t.text))
+		if len(lines) == 0 || (width > 0 && lineLen+1+n > width) {
+			lines = append(lines, nil)
+			lineLen = -1
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], i)
+		lineLen += 1 + n
+	}
+	return lines
+}
+
+// renderCueLines joins the wrapped lines of tokens, rendering each token
+// with render
+func renderCueLines(tokens []cueToken, lines [][]int, render func(i int, t cueToken) string) string {
+	rendered := make([]string, len(lines))
+	for l, line := range lines {
+		words := make([]string, len(line))
+		for j, i := range line {
+			words[j] = render(i, tokens[i])
+		}
+		rendered[l] = strings.Join(words, " ")
+	}
+	return strings.Join(rendered, "\n")
+}
+
+// TextOptions controls ToText. The zero value joins all segment text with
+// single spaces into one unwrapped paragraph.
+type TextOptions struct {
+	// LineWidth wraps lines at this many characters; 0 disables wrapping.
+	// Words longer than the width get a line of their own.
+	LineWidth int
+	// Timestamps prefixes each paragraph with its start time as [HH:MM:SS]
+	Timestamps bool
+	// ParagraphGap starts a new paragraph when the silence between one
+	// segment's End and the next one's Start is at least this many seconds;
+	// 0 disables paragraph breaks
+	ParagraphGap float64
+}
+
+// ToText renders the segments as readable plain text, with paragraphs
+// separated by blank lines
+func (r *TranscriptResult) ToText(opts TextOptions) string {
+	var paragraphs []string
+	var words []string
+	var paraStart, prevEnd float64
+
+	flush := func() {
+		if len(words) == 0 {
+			return
+		}
+		if opts.Timestamps {
+			s := int64(paraStart)
+			words = append([]string{fmt.Sprintf("[%02d:%02d:%02d]", s/3600, s/60%60, s%60)}, words...)
+		}
+		paragraphs = append(paragraphs, wrapWords(words, opts.LineWidth))
+		words = nil
+	}
+
+	for _, seg := range r.Segments {
+		segWords := strings.Fields(seg.Text)
+		if len(segWords) == 0 {
+			continue
+		}
+		if len(words) > 0 && opts.ParagraphGap > 0 && seg.Start-prevEnd >= opts.ParagraphGap {
+			flush()
+		}
+		if len(words) == 0 {
+			paraStart = seg.Start
+		}
+		words = append(words, segWords...)
+		prevEnd = seg.End
+	}
+	flush()
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// wrapWords joins words with spaces, breaking lines before they would exceed
+// width characters. A width of 0 or less keeps everything on one line.
+func wrapWords(words []string, width int) string {
+	var sb strings.Builder
+	lineLen := 0
+	for i, word := range words {
+		n := len([]rune(word))
+		if i > 0 {
+			if width > 0 && lineLen+1+n > width {
+				sb.WriteByte('\n')
+				lineLen = 0
+			} else {
+				sb.WriteByte(' ')
+				lineLen++
+			}
+		}
+		sb.WriteString(word)
+		lineLen += n
+	}
+	return sb.String()
+}
+
+// secondsToMillis converts seconds to whole milliseconds, never negative
+func secondsToMillis(seconds float64) int64 {
+	if seconds < 0 {
+		return 0
+	}
+	return int64(math.Round(seconds * 1000))
+}
+
+// formatCueTime formats milliseconds as HH:MM:SS<sep>mmm
+func formatCueTime(ms int64, sep string) string {
+	h := ms / 3600000
+	m := ms / 60000 % 60
+	s := ms / 1000 % 60
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", h, m, s, sep, ms%1000)
+}
+
+// ToCTM renders word-level timings in NIST CTM format, one word per line:
+//
+//	<recording> <channel> <start> <duration> <word> <confidence>
+//
+// Channel is always 1. Confidence is the word probability when the model
+// reported one and the alignment score otherwise. Words with empty text are
+// skipped.
+func (r *TranscriptResult) ToCTM(recordingID string) (string, error) {
+	if len(r.WordSegments) == 0 {
+		return "", fmt.Errorf("CTM output requires word-level timestamps")
+	}
+
+	var sb strings.Builder
+	for _, w := range r.WordSegments {
+		word := strings.TrimSpace(w.Word)
+		if word == "" {
+			continue
+		}
+		duration := w.End - w.Start
+		if duration < 0 {
+			duration = 0
+		}
+		confidence := w.Score
+		if w.Probability != nil {
+			confidence = *w.Probability
+		}
+		fmt.Fprintf(&sb, "%s 1 %.3f %.3f %s %.4f\n", recordingID, w.Start, duration, word, confidence)
+	}
+
+	return sb.String(), nil
+}
+
+// WriteCTM writes the result as a CTM file at path. The recording ID in each
+// line is the file name of path without its extension.
+func WriteCTM(result *TranscriptResult, path string) error {
+	recordingID := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	content, err := result.ToCTM(recordingID)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write CTM file: %w", err)
+	}
+	return nil
+}
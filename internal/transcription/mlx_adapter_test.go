@@ -0,0 +1,598 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"scriberr/internal/transcription/adapters"
+	"scriberr/internal/transcription/interfaces"
+)
+
+func TestMLXAdapterResultCacheSkipsSubprocess(t *testing.T) {
+	runner := &fakeMLXRunner{output: `{"text":"hello","language":"en","segments":[{"start":0,"end":1,"text":"hello"}]}`}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	adapter.SetResultCache(adapter.DefaultResultCacheDir())
+	input, procCtx := newMLXTestInput(t)
+
+	first, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx)
+	if err != nil {
+		t.Fatalf("First transcription failed: %v", err)
+	}
+	procCtx.JobID = "test-job-2"
+	second, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx)
+	if err != nil {
+		t.Fatalf("Second transcription failed: %v", err)
+	}
+
+	if calls := runner.callCount("uv"); calls != 1 {
+		t.Errorf("Expected the subprocess to run once, ran %d times", calls)
+	}
+	if second.Text != first.Text || second.JobID != "test-job-2" {
+		t.Errorf("Cached result mismatch: text %q (want %q), job ID %q", second.Text, first.Text, second.JobID)
+	}
+
+	if err := adapter.ClearResultCache(); err != nil {
+		t.Fatalf("ClearResultCache failed: %v", err)
+	}
+	if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx); err != nil {
+		t.Fatalf("Transcription after clearing the cache failed: %v", err)
+	}
+	if calls := runner.callCount("uv"); calls != 2 {
+		t.Errorf("Expected the subprocess to run again after clearing the cache, ran %d times in total", calls)
+	}
+}
+
+func TestMLXAdapterProcessingContextEnv(t *testing.T) {
+	t.Setenv("FAKE_UV_INHERITED", "inherited")
+	t.Setenv("OMP_NUM_THREADS", "")
+	runner := &fakeMLXRunner{
+		output: `{"text":"","language":"en","segments":[]}`,
+		// The model is cached
+		script: func(args []string) (string, error) { return "", nil },
+	}
+
+	cacheDir := t.TempDir()
+	adapter := adapters.NewMLXAdapter(t.TempDir()).WithOfflineCache(cacheDir)
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+
+	check := func(label string, want map[string]string) {
+		t.Helper()
+		for key, value := range want {
+			if got := envValue(runner.env, key); got != value {
+				t.Errorf("%s: %s = %q, want %q", label, key, got, value)
+			}
+		}
+	}
+
+	if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx); err != nil {
+		t.Fatalf("Transcription failed: %v", err)
+	}
+	check("Without Env", map[string]string{"FAKE_UV_INHERITED": "inherited", "OMP_NUM_THREADS": "", "HF_HOME": cacheDir})
+
+	procCtx.Env = map[string]string{"OMP_NUM_THREADS": "2", "HF_HOME": "/override"}
+	if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx); err != nil {
+		t.Fatalf("Transcription with Env failed: %v", err)
+	}
+	check("With Env", map[string]string{"FAKE_UV_INHERITED": "inherited", "OMP_NUM_THREADS": "2", "HF_HOME": "/override"})
+}
+
+// transcribeFakeChunks runs a chunked transcription of 30s of dummy audio in
+// the chunks [0s, 20s] and [15s, 30s], whose overlap is split at 17.5s. The
+// fake subprocess reports segments, a JSON array in the bridge's format, in
+// language as the result.
+func transcribeFakeChunks(t *testing.T, params map[string]interface{}, language, segments string) *interfaces.TranscriptResult {
+	t.Helper()
+	runner := &fakeMLXRunner{output: `{"language":"` + language + `","segments":` + segments + `}`}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+	input.Duration = 30 * time.Second
+	params["chunk_length"] = 20.0
+
+	result, err := adapter.Transcribe(context.Background(), input, params, procCtx)
+	if err != nil {
+		t.Fatalf("Chunked transcription failed: %v", err)
+	}
+	return result
+}
+
+// checkSegments compares the start times and texts of segments
+func checkSegments(t *testing.T, segments []interfaces.TranscriptSegment, starts []float64, texts []string) {
+	t.Helper()
+	if len(segments) != len(texts) {
+		t.Fatalf("Expected %d segments, got %d: %+v", len(texts), len(segments), segments)
+	}
+	for i, seg := range segments {
+		if seg.Start != starts[i] || seg.Text != texts[i] {
+			t.Errorf("Segment %d: expected %q at %.1fs, got %q at %.1fs", i, texts[i], starts[i], seg.Text, seg.Start)
+		}
+	}
+}
+
+func TestMLXAdapterChunksWithTimeOffset(t *testing.T) {
+	result := transcribeFakeChunks(t, map[string]interface{}{"time_offset": 100.0}, "en", `[
+		{"start": 2, "end": 6, "text": "alpha", "range_index": 0},
+		{"start": 16, "end": 17, "text": "beta", "range_index": 0},
+		{"start": 16, "end": 17, "text": "beta", "range_index": 1},
+		{"start": 24, "end": 28, "text": "gamma", "range_index": 1}
+	]`)
+	checkSegments(t, result.Segments, []float64{102, 116, 124}, []string{"alpha", "beta", "gamma"})
+}
+
+func TestMLXAdapterChunksBeforeRepetitions(t *testing.T) {
+	// Both chunks hear "beta"; the merge keeps the second copy, which must
+	// not have been collapsed into the first as a repetition beforehand
+	result := transcribeFakeChunks(t, map[string]interface{}{"repetition_threshold": 2}, "en", `[
+		{"start": 2, "end": 6, "text": "alpha", "range_index": 0},
+		{"start": 18, "end": 19, "text": "beta", "range_index": 0},
+		{"start": 18, "end": 19, "text": "beta", "range_index": 1},
+		{"start": 24, "end": 28, "text": "gamma", "range_index": 1}
+	]`)
+	checkSegments(t, result.Segments, []float64{2, 18, 24}, []string{"alpha", "beta", "gamma"})
+	if got := result.Metadata["repetitions_collapsed"]; got != "0" {
+		t.Errorf("Expected no collapsed repetitions, got %s", got)
+	}
+}
+
+func TestMLXAdapterChunksUnspacedLanguage(t *testing.T) {
+	result := transcribeFakeChunks(t, map[string]interface{}{}, "ja", `[
+		{"start": 2, "end": 6, "text": "こんにちは", "range_index": 0},
+		{"start": 16, "end": 17, "text": "世界", "range_index": 0},
+		{"start": 16, "end": 17, "text": "世界", "range_index": 1}
+	]`)
+	if want := "こんにちは世界"; result.Text != want {
+		t.Errorf("Text = %q, want %q", result.Text, want)
+	}
+}
+
+func TestMLXAdapterChunksPartialResult(t *testing.T) {
+	// The bridge died after writing the merged segments of both chunks
+	runner := &fakeMLXRunner{
+		err: errors.New("exit status 1"),
+		partial: `{"start": 2, "end": 6, "text": "alpha", "range_index": 0}
+{"start": 16, "end": 17, "text": "beta", "range_index": 0}
+{"start": 16, "end": 17, "text": "beta", "range_index": 1}
+`,
+	}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+	input.Duration = 30 * time.Second
+
+	result, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{"chunk_length": 20.0}, procCtx)
+	if !errors.Is(err, adapters.ErrPartialResult) {
+		t.Fatalf("Expected ErrPartialResult, got %v", err)
+	}
+	checkSegments(t, result.Segments, []float64{2, 16}, []string{"alpha", "beta"})
+}
+
+func TestMLXAdapterOfflineChecksQuantizedModel(t *testing.T) {
+	runner := &fakeMLXRunner{
+		output: `{"text":"","language":"en","segments":[]}`,
+		// Only the unquantized model is cached
+		script: func(args []string) (string, error) {
+			if args[len(args)-1] != "mlx-community/whisper-large-v3-mlx" {
+				return "", errors.New("exit status 1")
+			}
+			return "", nil
+		},
+	}
+	adapter := adapters.NewMLXAdapter(t.TempDir()).WithOfflineCache(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+
+	_, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{"quantization": "4bit"}, procCtx)
+	if !errors.Is(err, adapters.ErrModelNotCached) || !strings.Contains(err.Error(), "whisper-large-v3-mlx-4bit") {
+		t.Errorf("Expected ErrModelNotCached for the 4bit variant, got %v", err)
+	}
+
+	_, err = adapter.Transcribe(context.Background(), input, map[string]interface{}{"quantization": "none"}, procCtx)
+	if errors.Is(err, adapters.ErrModelNotCached) {
+		t.Errorf("The cached unquantized model was reported missing: %v", err)
+	}
+}
+
+func TestMLXAdapterAudioProbeRejectsInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{"no audio stream", `{"streams":[],"format":{"duration":"10.000000"}}`},
+		{"zero duration", `{"streams":[{"codec_type":"audio"}],"format":{"duration":"0.000000"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeMLXRunner{probe: tt.output}
+			adapter := adapters.NewMLXAdapter(t.TempDir())
+			adapter.SetCommandRunner(runner)
+			input, procCtx := newMLXTestInput(t)
+			procCtx.SkipAudioProbe = false
+
+			_, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx)
+			if !errors.Is(err, adapters.ErrAudioDecode) {
+				t.Errorf("Expected ErrAudioDecode, got %v", err)
+			}
+			if runner.callCount("uv") != 0 {
+				t.Error("The subprocess ran for rejected input")
+			}
+		})
+	}
+}
+
+func TestMLXAdapterAudioProbeCached(t *testing.T) {
+	runner := &fakeMLXRunner{
+		output: `{"text":"hello","language":"en","segments":[]}`,
+		probe:  `{"streams":[{"codec_type":"audio"}],"format":{"duration":"30.000000"}}`,
+	}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+	procCtx.SkipAudioProbe = false
+
+	if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx); err != nil {
+		t.Fatalf("First transcription failed: %v", err)
+	}
+	// Chunking takes the duration from the cached probe
+	if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{"chunk_length": 20.0}, procCtx); err != nil {
+		t.Fatalf("Chunked transcription failed: %v", err)
+	}
+	if calls := runner.callCount("ffprobe"); calls != 1 {
+		t.Errorf("Expected ffprobe to run once, ran %d times", calls)
+	}
+}
+
+func TestMLXAdapterAudioProbeMissingFFprobe(t *testing.T) {
+	// Without ffprobe the probe is skipped rather than failing the job
+	runner := &fakeMLXRunner{output: `{"text":"hello","language":"en","segments":[]}`}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+	procCtx.SkipAudioProbe = false
+
+	if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx); err != nil {
+		t.Fatalf("Transcription without ffprobe failed: %v", err)
+	}
+	if calls := runner.callCount("uv"); calls != 1 {
+		t.Errorf("Expected the subprocess to run once, ran %d times", calls)
+	}
+}
+
+func TestMLXAdapterTranscode(t *testing.T) {
+	runner := &fakeMLXRunner{output: `{"text":"hello","language":"en","segments":[]}`}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+	input.Format = "ogg"
+	params := map[string]interface{}{"transcode": true}
+
+	if _, err := adapter.Transcribe(context.Background(), input, params, procCtx); err != nil {
+		t.Fatalf("Transcription of ogg input failed: %v", err)
+	}
+	if calls := runner.callCount("ffmpeg"); calls != 1 {
+		t.Errorf("Expected ffmpeg to run once, ran %d times", calls)
+	}
+	if i := slices.Index(runner.args, "--audio"); i < 0 || i+1 >= len(runner.args) || filepath.Base(runner.args[i+1]) != "transcoded.wav" {
+		t.Errorf("Expected the transcoded WAV to be transcribed, got %v", runner.args)
+	}
+
+	// Supported formats are passed through as they are
+	input.Format = "wav"
+	if _, err := adapter.Transcribe(context.Background(), input, params, procCtx); err != nil {
+		t.Fatalf("Transcription of wav input failed: %v", err)
+	}
+	if calls := runner.callCount("ffmpeg"); calls != 1 {
+		t.Errorf("Expected WAV input not to be transcoded, ffmpeg ran %d times", calls)
+	}
+
+	runner.ffmpegErr = "audio.ogg: Invalid data found when processing input"
+	input.Format = "ogg"
+	_, err := adapter.Transcribe(context.Background(), input, params, procCtx)
+	if !errors.Is(err, adapters.ErrAudioDecode) {
+		t.Errorf("Expected ErrAudioDecode for undecodable input, got %v", err)
+	}
+	if calls := runner.callCount("uv"); calls != 2 {
+		t.Errorf("Expected the subprocess not to run for undecodable input, ran %d times in total", calls)
+	}
+}
+
+func TestMLXAdapterLanguageAliases(t *testing.T) {
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+
+	tests := []struct {
+		language string
+		want     string // expected --language argument; empty for none
+		wantErr  bool
+	}{
+		{language: "en", want: "en"},
+		{language: "EN", want: "en"},
+		{language: " en ", want: "en"},
+		{language: "en-US", want: "en"},
+		{language: "en_GB", want: "en"},
+		{language: "English", want: "en"},
+		{language: "pt-BR", want: "pt"},
+		{language: "zh-Hans-CN", want: "zh"},
+		{language: "japanese", want: "ja"},
+		{language: "auto", want: ""},
+		{language: "", want: ""},
+		{language: "klingon", wantErr: true},
+		{language: "xx-US", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			input, procCtx := newMLXTestInput(t)
+			procCtx.DryRun = true
+
+			result, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{"language": tt.language}, procCtx)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "supported languages are") {
+					t.Fatalf("Expected an unsupported language error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Transcribe failed: %v", err)
+			}
+
+			var argv []string
+			if err := json.Unmarshal([]byte(result.Metadata["argv"]), &argv); err != nil {
+				t.Fatalf("Failed to parse argv: %v", err)
+			}
+			got := ""
+			for i, arg := range argv {
+				if arg == "--language" && i+1 < len(argv) {
+					got = argv[i+1]
+				}
+			}
+			if got != tt.want {
+				t.Errorf("--language = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMLXAdapterParameterSchemaJSON(t *testing.T) {
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+
+	data, err := adapter.ParameterSchemaJSON()
+	if err != nil {
+		t.Fatalf("ParameterSchemaJSON failed: %v", err)
+	}
+	var schema struct {
+		ModelID    string                       `json:"model_id"`
+		Groups     []string                     `json:"groups"`
+		Parameters []interfaces.ParameterSchema `json:"parameters"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Failed to parse schema JSON: %v", err)
+	}
+	if schema.ModelID != "mlx_whisper" {
+		t.Errorf("model_id = %q, want mlx_whisper", schema.ModelID)
+	}
+	if len(schema.Groups) == 0 || schema.Groups[0] != "basic" {
+		t.Errorf("groups = %v, want basic first", schema.Groups)
+	}
+
+	params := make(map[string]interfaces.ParameterSchema, len(schema.Parameters))
+	for _, p := range schema.Parameters {
+		params[p.Name] = p
+	}
+	for name, group := range map[string]string{
+		"model":                      "basic",
+		"language":                   "basic",
+		"quantization":               "advanced",
+		"beam_size":                  "quality",
+		"condition_on_previous_text": "advanced",
+		"no_speech_threshold":        "advanced",
+	} {
+		p, ok := params[name]
+		if !ok {
+			t.Errorf("Parameter %s missing from schema JSON", name)
+			continue
+		}
+		if p.Group != group {
+			t.Errorf("%s group = %q, want %q", name, p.Group, group)
+		}
+	}
+	if model := params["model"]; model.Type != "string" || model.Default == nil || len(model.Options) == 0 {
+		t.Errorf("model = %+v, want a string with a default and options", model)
+	}
+}
+
+func TestMLXAdapterDetectLanguageStreamedInput(t *testing.T) {
+	var spooled string
+	runner := &fakeMLXRunner{
+		// The script gets the audio path, the model and the offline flag
+		script: func(args []string) (string, error) {
+			data, err := os.ReadFile(args[len(args)-3])
+			spooled = string(data)
+			if err != nil {
+				return "", err
+			}
+			return `{"language": "de", "probability": 0.93}` + "\n", nil
+		},
+	}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input := interfaces.AudioInput{Reader: strings.NewReader("streamed audio"), Format: "wav"}
+	procCtx := interfaces.ProcessingContext{Env: map[string]string{"OMP_NUM_THREADS": "3"}}
+
+	language, probability, err := adapter.DetectLanguage(context.Background(), input, procCtx)
+	if err != nil {
+		t.Fatalf("DetectLanguage failed: %v", err)
+	}
+	if language != "de" || probability != 0.93 {
+		t.Errorf("Detected %s (%.2f), want de (0.93)", language, probability)
+	}
+	if spooled != "streamed audio" {
+		t.Errorf("Expected the script to read the spooled input, got %q", spooled)
+	}
+	if got := envValue(runner.env, "OMP_NUM_THREADS"); got != "3" {
+		t.Errorf("OMP_NUM_THREADS = %q, want the request's 3", got)
+	}
+}
+
+func TestMLXAdapterDetectLanguageMaxConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	runner := &fakeMLXRunner{
+		script: func(args []string) (string, error) {
+			mu.Lock()
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+			time.Sleep(50 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return `{"language": "en", "probability": 1.0}`, nil
+		},
+	}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	adapter.SetMaxConcurrency(1)
+	input, procCtx := newMLXTestInput(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		jobCtx := procCtx
+		jobCtx.JobID = "detect-" + strconv.Itoa(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := adapter.DetectLanguage(context.Background(), input, jobCtx); err != nil {
+				t.Errorf("DetectLanguage failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if peak != 1 {
+		t.Errorf("%d detections ran at once, limit is 1", peak)
+	}
+}
+
+func TestMLXAdapterKeptTempDirIsCleanedUp(t *testing.T) {
+	runner := &fakeMLXRunner{output: `{"text":"hello","language":"en","segments":[]}`}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+	procCtx.KeepTemp = true
+	adapter.SetTempDirectory(procCtx.TempDirectory, 0)
+
+	result, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx)
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	tempDir := result.Metadata["temp_dir"]
+	if _, err := os.Stat(tempDir); err != nil {
+		t.Fatalf("Temp directory was not kept: %v", err)
+	}
+
+	removed, err := adapter.CleanupStaleTempDirs(0)
+	if err != nil {
+		t.Fatalf("CleanupStaleTempDirs failed: %v", err)
+	}
+	if _, err := os.Stat(tempDir); removed != 1 || !os.IsNotExist(err) {
+		t.Errorf("Expected the kept temp directory to be removed, removed %d", removed)
+	}
+}
+
+// labelAnalyzer labels every segment with Label
+type labelAnalyzer struct {
+	Label string
+}
+
+func (a labelAnalyzer) AnalyzeSegment(ctx context.Context, input interfaces.SegmentAnalysisInput) (map[string]string, error) {
+	return map[string]string{"label": a.Label}, nil
+}
+
+func TestMLXAdapterResultCacheKeyedByEnvAndAnalyzer(t *testing.T) {
+	runner := &fakeMLXRunner{output: `{"text":"hello","language":"en","segments":[{"start":0,"end":1,"text":"hello"}]}`}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	adapter.SetResultCache(t.TempDir())
+	input, procCtx := newMLXTestInput(t)
+
+	steps := []struct {
+		name  string
+		setup func()
+		runs  int
+	}{
+		{"first run", func() {}, 1},
+		{"same request", func() {}, 1},
+		{"different Env", func() { procCtx.Env = map[string]string{"OMP_NUM_THREADS": "2"} }, 2},
+		{"analyzer added", func() { adapter.SetSegmentAnalyzer(labelAnalyzer{Label: "a"}) }, 3},
+		{"analyzer reconfigured", func() { adapter.SetSegmentAnalyzer(labelAnalyzer{Label: "b"}) }, 4},
+		{"same analyzer", func() {}, 4},
+	}
+	for _, step := range steps {
+		step.setup()
+		if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx); err != nil {
+			t.Fatalf("%s: Transcribe failed: %v", step.name, err)
+		}
+		if calls := runner.callCount("uv"); calls != step.runs {
+			t.Errorf("%s: expected %d subprocess runs in total, got %d", step.name, step.runs, calls)
+		}
+	}
+}
+
+// memorySink keeps the output files put into it
+type memorySink struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (s *memorySink) Put(name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.files == nil {
+		s.files = make(map[string][]byte)
+	}
+	s.files[name] = data
+	return nil
+}
+
+func TestMLXAdapterSkipIfFreshWithSink(t *testing.T) {
+	runner := &fakeMLXRunner{output: `{"text":"hello","language":"en","segments":[]}`}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+	sink := &memorySink{}
+	procCtx.OutputSink = sink
+
+	if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{"skip_if_fresh": true}, procCtx); err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+
+	if local, _ := filepath.Glob(filepath.Join(procCtx.OutputDirectory, "*.json")); len(local) > 0 {
+		t.Errorf("Expected nothing in the output directory with a sink, found %v", local)
+	}
+	var saved interfaces.TranscriptResult
+	for name, data := range sink.files {
+		if strings.HasSuffix(name, ".json") {
+			if err := json.Unmarshal(data, &saved); err != nil {
+				t.Fatalf("Failed to parse %s from the sink: %v", name, err)
+			}
+		}
+	}
+	if saved.Metadata["params_hash"] == "" {
+		t.Errorf("Expected the sink's result to carry the parameter fingerprint, got %+v", saved.Metadata)
+	}
+}
@@ -0,0 +1,265 @@
+//go:build !windows
+// +build !windows
+
+package transcription
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"scriberr/internal/transcription/adapters"
+)
+
+// installFakeUV puts an executable "uv" shell script with the given body first
+// on PATH, so the MLX adapter runs it instead of the real uv
+func installFakeUV(t *testing.T, body string) {
+	t.Helper()
+	installFakeCommand(t, "uv", body)
+}
+
+// installFakeCommand puts an executable shell script called name with the
+// given body first on PATH
+func installFakeCommand(t *testing.T, name, body string) {
+	t.Helper()
+	binDir := t.TempDir()
+	script := "#!/bin/sh\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake %s: %v", name, err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// processAlive reports whether pid is running (zombies count as dead)
+func processAlive(pid int) bool {
+	if err := syscall.Kill(pid, 0); err != nil {
+		return false
+	}
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat))
+	return len(fields) < 3 || fields[2] != "Z"
+}
+
+func TestMLXAdapterCancelKillsProcessTree(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	t.Setenv("FAKE_UV_PIDFILE", pidFile)
+	installFakeUV(t, `sleep 30 &
+echo $! > "$FAKE_UV_PIDFILE"
+wait`)
+
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	input, procCtx := newMLXTestInput(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := adapter.Transcribe(ctx, input, map[string]interface{}{}, procCtx)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected a context deadline error, got %v", err)
+	}
+	if elapsed > 3*time.Second {
+		t.Errorf("Transcribe took %v to return after cancellation", elapsed)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("Fake uv did not record its child: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("Invalid child pid %q: %v", data, err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for processAlive(pid) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if processAlive(pid) {
+		syscall.Kill(pid, syscall.SIGKILL)
+		t.Errorf("Child process %d survived cancellation", pid)
+	}
+
+	jobTempDir := filepath.Join(procCtx.TempDirectory, "mlx_whisper", procCtx.JobID)
+	if _, err := os.Stat(jobTempDir); !os.IsNotExist(err) {
+		t.Errorf("Temp directory %s was not cleaned up", jobTempDir)
+	}
+}
+
+func TestMLXAdapterPrepareEnvironmentCancelResumes(t *testing.T) {
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	if available, reason := adapter.Available(); !available {
+		t.Skip(reason)
+	}
+
+	dir := t.TempDir()
+	t.Setenv("FAKE_UV_ADDING", filepath.Join(dir, "adding"))
+	t.Setenv("FAKE_UV_RESUME", filepath.Join(dir, "resume"))
+	installFakeUV(t, `case "$1" in
+--version) echo "uv 0.5.2 (abc123 2024-11-14)" ;;
+run) exit 1 ;;
+init) touch pyproject.toml ;;
+add)
+	[ -f "$FAKE_UV_RESUME" ] && exit 0
+	touch "$FAKE_UV_ADDING"
+	sleep 30 ;;
+esac`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- adapter.PrepareEnvironment(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(os.Getenv("FAKE_UV_ADDING")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Fake uv add never started")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected a context cancellation error, got %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("PrepareEnvironment did not return after cancellation")
+	}
+	if adapter.Ready() {
+		t.Error("Adapter is ready after a cancelled setup")
+	}
+
+	// Re-running completes the interrupted install
+	if err := os.WriteFile(os.Getenv("FAKE_UV_RESUME"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write resume marker: %v", err)
+	}
+	if err := adapter.PrepareEnvironment(context.Background()); err != nil {
+		t.Fatalf("Resumed PrepareEnvironment failed: %v", err)
+	}
+	if !adapter.Ready() {
+		t.Error("Adapter is not ready after resuming setup")
+	}
+}
+
+func TestCheckUVMissing(t *testing.T) {
+	emptyDir := t.TempDir()
+	t.Setenv("PATH", emptyDir)
+
+	err := adapters.CheckUV(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error when uv is not on PATH")
+	}
+	if !strings.Contains(err.Error(), "uv not found on PATH; install from https://astral.sh/uv") {
+		t.Errorf("Error should explain how to install uv, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), emptyDir) {
+		t.Errorf("Error should include the PATH that was searched, got: %v", err)
+	}
+}
+
+func TestCheckUVVersion(t *testing.T) {
+	installFakeUV(t, `echo "uv 0.1.45 (abc123 2024-05-01)"`)
+	err := adapters.CheckUV(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "too old") {
+		t.Errorf("Expected an old uv to be rejected, got: %v", err)
+	}
+
+	installFakeUV(t, `echo "uv 0.5.2 (abc123 2024-11-14)"`)
+	if err := adapters.CheckUV(context.Background()); err != nil {
+		t.Errorf("Expected a recent uv to be accepted, got: %v", err)
+	}
+}
+
+func TestMLXAdapterMaxConcurrency(t *testing.T) {
+	const limit = 2
+	runDir := t.TempDir()
+	countLog := filepath.Join(t.TempDir(), "counts")
+	t.Setenv("FAKE_UV_RUNS", runDir)
+	t.Setenv("FAKE_UV_LOG", countLog)
+	// Each run marks itself as running, records how many runs it can see and
+	// stays alive long enough for the others to overlap
+	installFakeUV(t, `touch "$FAKE_UV_RUNS/$$"
+ls "$FAKE_UV_RUNS" | wc -l >> "$FAKE_UV_LOG"
+sleep 0.3
+rm "$FAKE_UV_RUNS/$$"
+exit 1`)
+
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetMaxConcurrency(limit)
+
+	var wg sync.WaitGroup
+	for i := 0; i < limit+2; i++ {
+		input, procCtx := newMLXTestInput(t)
+		procCtx.JobID = "test-job-" + strconv.Itoa(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx)
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(countLog)
+	if err != nil {
+		t.Fatalf("Fake uv never ran: %v", err)
+	}
+	counts := strings.Fields(string(data))
+	if len(counts) != limit+2 {
+		t.Errorf("Expected %d subprocess runs, got %d", limit+2, len(counts))
+	}
+	for _, c := range counts {
+		if n, _ := strconv.Atoi(c); n > limit {
+			t.Errorf("%d subprocesses ran at once, limit is %d", n, limit)
+		}
+	}
+}
+
+func TestMLXAdapterConcurrentJobsKeepSeparateLogs(t *testing.T) {
+	// The fake uv logs its arguments, which include the job's temp directory
+	installFakeUV(t, `echo "$@"
+sleep 0.2
+exit 1`)
+
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	input, procCtx := newMLXTestInput(t)
+	jobIDs := []string{"job-a", "job-b"}
+
+	var wg sync.WaitGroup
+	for _, jobID := range jobIDs {
+		jobCtx := procCtx
+		jobCtx.JobID = jobID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			adapter.Transcribe(context.Background(), input, map[string]interface{}{}, jobCtx)
+		}()
+	}
+	wg.Wait()
+
+	for i, jobID := range jobIDs {
+		data, err := os.ReadFile(filepath.Join(procCtx.OutputDirectory, "mlx_transcription-"+jobID+".log"))
+		if err != nil {
+			t.Fatalf("Missing log for %s: %v", jobID, err)
+		}
+		other := jobIDs[1-i]
+		if !strings.Contains(string(data), jobID) || strings.Contains(string(data), other) {
+			t.Errorf("Log for %s should only contain its own output, got:\n%s", jobID, data)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"scriberr/internal/transcription/adapters"
+	"scriberr/internal/transcription/interfaces"
+)
+
+// newMLXTestInput creates a dummy audio file and a processing context rooted
+// in a temp directory
+func newMLXTestInput(t *testing.T) (interfaces.AudioInput, interfaces.ProcessingContext) {
+	t.Helper()
+	dir := t.TempDir()
+	audioPath := filepath.Join(dir, "audio.wav")
+	if err := os.WriteFile(audioPath, []byte("not really audio"), 0644); err != nil {
+		t.Fatalf("Failed to write audio file: %v", err)
+	}
+
+	input := interfaces.AudioInput{FilePath: audioPath, Format: "wav", Size: 16}
+	procCtx := interfaces.ProcessingContext{
+		JobID:           "test-job",
+		OutputDirectory: dir,
+		TempDirectory:   filepath.Join(dir, "tmp"),
+		// The file is not real audio
+		SkipAudioProbe: true,
+	}
+	return input, procCtx
+}
+
+// fakeMLXRunner stands in for the MLX adapter's subprocesses, so tests need
+// neither uv nor ffmpeg installed. Every run is counted by program name.
+//
+// uv runs record their environment. The transcription run also records its
+// command line, writes output to the --output path and fails with err,
+// printing stderr and leaving partial as its partial output, if err is set. "uv run ... python -c" helper scripts are
+// answered by script, and fail if it is nil. ffprobe prints probe, or is not found if probe is empty;
+// ffmpeg creates its output file, or fails printing ffmpegErr if that is set.
+type fakeMLXRunner struct {
+	output    string
+	stderr    string
+	err       error
+	partial   string
+	script    func(args []string) (string, error)
+	probe     string
+	ffmpegErr string
+
+	mu    sync.Mutex
+	args  []string
+	env   []string
+	calls map[string]int
+}
+
+func (f *fakeMLXRunner) Run(ctx context.Context, cmd *exec.Cmd) error {
+	name := filepath.Base(cmd.Args[0])
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[name]++
+	f.mu.Unlock()
+
+	switch {
+	case name == "ffprobe":
+		if f.probe == "" {
+			return &exec.Error{Name: name, Err: exec.ErrNotFound}
+		}
+		fmt.Fprint(cmd.Stdout, f.probe)
+		return nil
+	case name == "ffmpeg":
+		if f.ffmpegErr != "" {
+			fmt.Fprintln(cmd.Stderr, f.ffmpegErr)
+			return errors.New("exit status 1")
+		}
+		return os.WriteFile(cmd.Args[len(cmd.Args)-1], []byte("not really audio"), 0644)
+	case slices.Contains(cmd.Args, "-c"):
+		f.mu.Lock()
+		f.env = cmd.Env
+		f.mu.Unlock()
+		if f.script == nil {
+			return errors.New("exit status 1")
+		}
+		out, err := f.script(cmd.Args)
+		if cmd.Stdout != nil {
+			fmt.Fprint(cmd.Stdout, out)
+		}
+		return err
+	}
+
+	f.mu.Lock()
+	f.args = cmd.Args
+	f.env = cmd.Env
+	f.mu.Unlock()
+
+	if f.stderr != "" && cmd.Stderr != nil {
+		fmt.Fprintln(cmd.Stderr, f.stderr)
+	}
+	if f.err != nil {
+		if i := slices.Index(cmd.Args, "--partial-output"); i >= 0 && f.partial != "" {
+			os.WriteFile(cmd.Args[i+1], []byte(f.partial), 0644)
+		}
+		return f.err
+	}
+	if i := slices.Index(cmd.Args, "--output"); i >= 0 && i+1 < len(cmd.Args) {
+		return os.WriteFile(cmd.Args[i+1], []byte(f.output), 0644)
+	}
+	return errors.New("no --output argument")
+}
+
+// callCount returns how many times program ran
+func (f *fakeMLXRunner) callCount(program string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[program]
+}
+
+// envValue returns the value key takes in env, where later entries win as
+// they do for exec.Cmd
+func envValue(env []string, key string) string {
+	value := ""
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			value = v
+		}
+	}
+	return value
+}
+
+func TestMLXAdapterFakeRunner(t *testing.T) {
+	runner := &fakeMLXRunner{
+		output: `{"text":" hello world","language":"en","segments":[` +
+			`{"start":0,"end":1.5,"text":" hello world","avg_logprob":null,` +
+			`"words":[{"word":" hello","start":0,"end":0.7,"probability":0.9},{"word":" world","start":0.7,"end":1.5,"probability":0.8}]}]}`,
+	}
+	adapter := adapters.NewMLXAdapter(t.TempDir())
+	adapter.SetCommandRunner(runner)
+	input, procCtx := newMLXTestInput(t)
+
+	result, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{"best_of": 5, "language": "en"}, procCtx)
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+
+	for _, want := range [][]string{{"--best-of", "5"}, {"--language", "en"}} {
+		i := slices.Index(runner.args, want[0])
+		if i < 0 || i+1 >= len(runner.args) || runner.args[i+1] != want[1] {
+			t.Errorf("Expected %s %s in %v", want[0], want[1], runner.args)
+		}
+	}
+	if len(result.Segments) != 1 || result.Segments[0].Text != "hello world" {
+		t.Fatalf("Unexpected segments: %+v", result.Segments)
+	}
+	if seg := result.Segments[0]; seg.AvgLogProb != nil || len(seg.Words) != 2 || seg.Words[1].Word != "world" {
+		t.Errorf("Unexpected segment details: %+v", seg)
+	}
+
+	// mlx-whisper has no beam search
+	if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{"beam_size": 5}, procCtx); err == nil {
+		t.Error("Expected beam_size 5 to be rejected")
+	}
+
+	runner.err = errors.New("exit status 1")
+	runner.stderr = "huggingface_hub.errors.RepositoryNotFoundError: 404 Client Error"
+	if _, err := adapter.Transcribe(context.Background(), input, map[string]interface{}{}, procCtx); !errors.Is(err, adapters.ErrModelNotFound) {
+		t.Errorf("Expected ErrModelNotFound, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -39,14 +40,43 @@ func GetRegistry() *ModelRegistry {
 	return globalRegistry
 }
 
-// RegisterTranscriptionAdapter registers a transcription model adapter
+// ErrDuplicateModelID is returned when a model ID is already registered
+var ErrDuplicateModelID = errors.New("model ID already registered")
+
+// RegisterTranscriptionAdapterE registers a transcription model adapter like
+// RegisterTranscriptionAdapter, but fails with ErrDuplicateModelID instead of
+// replacing an adapter of any kind already registered under modelID
+func RegisterTranscriptionAdapterE(modelID string, adapter interfaces.TranscriptionAdapter) error {
+	registry := GetRegistry()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if _, exists := registry.capabilities[modelID]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateModelID, modelID)
+	}
+	registry.addTranscriptionAdapter(modelID, adapter)
+	return nil
+}
+
+// RegisterTranscriptionAdapter registers a transcription model adapter,
+// replacing any adapter already registered under modelID. Replacements are
+// logged as warnings since they usually mean two adapters share an ID; use
+// RegisterTranscriptionAdapterE to reject them, or Unregister first.
 func RegisterTranscriptionAdapter(modelID string, adapter interfaces.TranscriptionAdapter) {
 	registry := GetRegistry()
 	registry.mu.Lock()
 	defer registry.mu.Unlock()
 
-	registry.transcriptionAdapters[modelID] = adapter
-	registry.capabilities[modelID] = adapter.GetCapabilities()
+	if _, exists := registry.capabilities[modelID]; exists {
+		logger.Warn("Replacing registered adapter", "model_id", modelID)
+	}
+	registry.addTranscriptionAdapter(modelID, adapter)
+}
+
+// addTranscriptionAdapter stores adapter under modelID; r.mu must be held
+func (r *ModelRegistry) addTranscriptionAdapter(modelID string, adapter interfaces.TranscriptionAdapter) {
+	r.transcriptionAdapters[modelID] = adapter
+	r.capabilities[modelID] = adapter.GetCapabilities()
 
 	logger.Debug("Registered transcription adapter",
 		"model_id", modelID,
@@ -84,6 +114,29 @@ func RegisterCompositeAdapter(modelID string, adapter interfaces.CompositeAdapte
 		"display_name", adapter.GetCapabilities().DisplayName)
 }
 
+// Unregister removes the adapter registered under modelID, whatever its kind.
+// It is a no-op if modelID is not registered.
+func Unregister(modelID string) {
+	registry := GetRegistry()
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	delete(registry.transcriptionAdapters, modelID)
+	delete(registry.diarizationAdapters, modelID)
+	delete(registry.compositeAdapters, modelID)
+	delete(registry.capabilities, modelID)
+}
+
+// Has reports whether an adapter of any kind is registered under modelID
+func Has(modelID string) bool {
+	registry := GetRegistry()
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	_, exists := registry.capabilities[modelID]
+	return exists
+}
+
 // GetTranscriptionAdapter retrieves a transcription adapter by ID
 func (r *ModelRegistry) GetTranscriptionAdapter(modelID string) (interfaces.TranscriptionAdapter, error) {
 	r.mu.RLock()
@@ -405,6 +458,12 @@ func (r *ModelRegistry) InitializeModels(ctx context.Context) error {
 		PrepareEnvironment(context.Context) error
 	}, typeName string) {
 		defer wg.Done()
+		if checker, ok := adapter.(interfaces.AvailabilityChecker); ok {
+			if available, reason := checker.Available(); !available {
+				logger.Info(fmt.Sprintf("Skipping unavailable %s model", typeName), "model_id", id, "reason", reason)
+				return
+			}
+		}
 		logger.Debug(fmt.Sprintf("Initializing %s model", typeName), "model_id", id)
 		if err := adapter.PrepareEnvironment(ctx); err != nil {
 			logger.Error(fmt.Sprintf("Failed to initialize %s model", typeName),
@@ -455,6 +514,81 @@ func (r *ModelRegistry) InitializeModels(ctx context.Context) error {
 	return nil
 }
 
+// IsAvailable reports whether the adapter registered as modelID can run on
+// this machine and, if not, why. Unknown models are reported as unavailable.
+func (r *ModelRegistry) IsAvailable(modelID string) (bool, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var adapter interface{}
+	if a, exists := r.transcriptionAdapters[modelID]; exists {
+		adapter = a
+	} else if a, exists := r.diarizationAdapters[modelID]; exists {
+		adapter = a
+	} else if a, exists := r.compositeAdapters[modelID]; exists {
+		adapter = a
+	} else {
+		return false, fmt.Sprintf("model %s is not registered", modelID)
+	}
+
+	if checker, ok := adapter.(interfaces.AvailabilityChecker); ok {
+		return checker.Available()
+	}
+	return true, ""
+}
+
+// GetAvailableTranscriptionModels returns the transcription models that can
+// run on this machine
+func (r *ModelRegistry) GetAvailableTranscriptionModels() []string {
+	var models []string
+	for _, modelID := range r.GetTranscriptionModels() {
+		if ok, _ := r.IsAvailable(modelID); ok {
+			models = append(models, modelID)
+		}
+	}
+	return models
+}
+
+// HealthCheckAll checks every registered adapter and returns the result by
+// model ID; nil means healthy. Adapters without a HealthCheck are checked for
+// availability and readiness instead.
+func (r *ModelRegistry) HealthCheckAll(ctx context.Context) map[string]error {
+	r.mu.RLock()
+	adapters := make(map[string]interfaces.ModelAdapter)
+	for id, adapter := range r.transcriptionAdapters {
+		adapters[id] = adapter
+	}
+	for id, adapter := range r.diarizationAdapters {
+		adapters[id] = adapter
+	}
+	for id, adapter := range r.compositeAdapters {
+		adapters[id] = adapter
+	}
+	r.mu.RUnlock()
+
+	results := make(map[string]error, len(adapters))
+	for id, adapter := range adapters {
+		switch a := adapter.(type) {
+		case interfaces.HealthChecker:
+			results[id] = a.HealthCheck(ctx)
+		default:
+			if available, reason := r.IsAvailable(id); !available {
+				results[id] = fmt.Errorf("model %s is unavailable: %s", id, reason)
+			} else if !adapter.IsReady(ctx) {
+				results[id] = fmt.Errorf("model %s is not ready", id)
+			} else {
+				results[id] = nil
+			}
+		}
+	}
+	return results
+}
+
+// HealthCheckAll checks every adapter in the global registry
+func HealthCheckAll(ctx context.Context) map[string]error {
+	return GetRegistry().HealthCheckAll(ctx)
+}
+
 // GetModelStatus returns the status of all registered models
 func (r *ModelRegistry) GetModelStatus(ctx context.Context) map[string]bool {
 	r.mu.RLock()
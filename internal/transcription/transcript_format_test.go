@@ -0,0 +1,171 @@
+package transcription
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"scriberr/internal/transcription/interfaces"
+)
+
+func subtitleTestResult() *interfaces.TranscriptResult {
+	return &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 2.5, Text: " Hello there. "},
+			{Start: 3, End: 3, Text: "Blip"},
+			{Start: 4, End: 5, Text: "   "},
+			{Start: 3599.5, End: 3601.25, Text: "Across the hour"},
+		},
+	}
+}
+
+// cueTimePattern matches HH:MM:SS followed by a separator and milliseconds
+var cueTimePattern = regexp.MustCompile(`(\d{2}):(\d{2}):(\d{2})[,.](\d{3})`)
+
+// parseCueTime converts a cue timestamp back to seconds
+func parseCueTime(t *testing.T, s string) float64 {
+	t.Helper()
+	m := cueTimePattern.FindStringSubmatch(s)
+	if m == nil {
+		t.Fatalf("Invalid cue timestamp %q", s)
+	}
+	h, _ := strconv.Atoi(m[1])
+	min, _ := strconv.Atoi(m[2])
+	sec, _ := strconv.Atoi(m[3])
+	ms, _ := strconv.Atoi(m[4])
+	return float64(h*3600+min*60+sec) + float64(ms)/1000
+}
+
+func TestTranscriptResultToSRT(t *testing.T) {
+	expected := "1\n00:00:00,000 --> 00:00:02,500\nHello there.\n\n" +
+		"2\n00:00:03,000 --> 00:00:03,001\nBlip\n\n" +
+		"3\n00:59:59,500 --> 01:00:01,250\nAcross the hour\n\n"
+
+	if got := subtitleTestResult().ToSRT(); got != expected {
+		t.Errorf("Unexpected SRT output:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestTranscriptResultToVTT(t *testing.T) {
+	expected := "WEBVTT\n\n" +
+		"1\n00:00:00.000 --> 00:00:02.500\nHello there.\n\n" +
+		"2\n00:00:03.000 --> 00:00:03.001\nBlip\n\n" +
+		"3\n00:59:59.500 --> 01:00:01.250\nAcross the hour\n\n"
+
+	if got := subtitleTestResult().ToVTT(); got != expected {
+		t.Errorf("Unexpected VTT output:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestSubtitleTimestampsRoundTrip(t *testing.T) {
+	result := subtitleTestResult()
+	for _, format := range []string{result.ToSRT(), strings.TrimPrefix(result.ToVTT(), "WEBVTT\n\n")} {
+		var parsed []interfaces.TranscriptSegment
+		for _, cue := range strings.Split(strings.TrimSpace(format), "\n\n") {
+			lines := strings.Split(cue, "\n")
+			if len(lines) != 3 {
+				t.Fatalf("Cue should have 3 lines, got %q", cue)
+			}
+			times := strings.Split(lines[1], " --> ")
+			parsed = append(parsed, interfaces.TranscriptSegment{
+				Start: parseCueTime(t, times[0]),
+				End:   parseCueTime(t, times[1]),
+				Text:  lines[2],
+			})
+		}
+
+		if len(parsed) != 3 {
+			t.Fatalf("Expected 3 cues, got %d", len(parsed))
+		}
+		if parsed[0].Start != 0 || parsed[0].End != 2.5 || parsed[0].Text != "Hello there." {
+			t.Errorf("First cue did not round-trip: %+v", parsed[0])
+		}
+		if parsed[2].Start != 3599.5 || parsed[2].End != 3601.25 {
+			t.Errorf("Hour-crossing cue did not round-trip: %+v", parsed[2])
+		}
+	}
+}
+
+func TestTranscriptResultToTextDefault(t *testing.T) {
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 1, Text: " Hello there."},
+			{Start: 1, End: 2, Text: ""},
+			{Start: 30, End: 31, Text: "General  Kenobi. "},
+		},
+	}
+
+	if got := result.ToText(interfaces.TextOptions{}); got != "Hello there. General Kenobi." {
+		t.Errorf("Unexpected default text: %q", got)
+	}
+}
+
+func TestTranscriptResultToTextParagraphs(t *testing.T) {
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 2, Text: "The quick brown fox"},
+			{Start: 2.5, End: 4, Text: "jumps over the lazy dog."},
+			{Start: 3725, End: 3727, Text: "Much later."},
+		},
+	}
+
+	got := result.ToText(interfaces.TextOptions{LineWidth: 20, Timestamps: true, ParagraphGap: 2})
+	expected := "[00:00:00] The quick\n" +
+		"brown fox jumps over\n" +
+		"the lazy dog.\n" +
+		"\n" +
+		"[01:02:05] Much\n" +
+		"later."
+	if got != expected {
+		t.Errorf("Unexpected paragraph text:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestSubtitleLineWrapping(t *testing.T) {
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 6, Text: "one two three four five six"},
+		},
+	}
+
+	// Two lines of at most 9 characters hold three words, so the rest moves
+	// to a second cue; both have 11 letters and get half the time
+	expected := "1\n00:00:00,000 --> 00:00:03,000\none two\nthree\n\n" +
+		"2\n00:00:03,000 --> 00:00:06,000\nfour five\nsix\n\n"
+	got := result.ToSRTWithOptions(interfaces.SubtitleOptions{MaxLineWidth: 9})
+	if got != expected {
+		t.Errorf("Unexpected wrapped SRT output:\n%s\nwant:\n%s", got, expected)
+	}
+
+	got = result.ToSRTWithOptions(interfaces.SubtitleOptions{MaxLineWidth: 9, MaxLineCount: 3})
+	if !strings.Contains(got, "one two\nthree\nfour five\n") || strings.Count(got, "-->") != 2 {
+		t.Errorf("Expected three lines in the first cue, got:\n%s", got)
+	}
+}
+
+func TestSubtitleHighlightWords(t *testing.T) {
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 2, Text: "Hi there", Words: []interfaces.WordTiming{
+				{Start: 0.2, End: 0.8, Word: "Hi"},
+				{Start: 1, End: 1.8, Word: "there"},
+			}},
+			{Start: 3, End: 4, Text: "No words"},
+		},
+	}
+
+	expected := "1\n00:00:00,000 --> 00:00:01,000\n<font color=\"#FFFF00\">Hi</font> there\n\n" +
+		"2\n00:00:01,000 --> 00:00:02,000\nHi <font color=\"#FFFF00\">there</font>\n\n" +
+		"3\n00:00:03,000 --> 00:00:04,000\nNo words\n\n"
+	if got := result.ToSRTWithOptions(interfaces.SubtitleOptions{HighlightWords: true}); got != expected {
+		t.Errorf("Unexpected highlighted SRT output:\n%s\nwant:\n%s", got, expected)
+	}
+
+	expected = "WEBVTT\n\n" +
+		"1\n00:00:00.000 --> 00:00:02.000\nHi <00:00:01.000>there\n\n" +
+		"2\n00:00:03.000 --> 00:00:04.000\nNo words\n\n"
+	if got := result.ToVTTWithOptions(interfaces.SubtitleOptions{HighlightWords: true}); got != expected {
+		t.Errorf("Unexpected highlighted VTT output:\n%s\nwant:\n%s", got, expected)
+	}
+}
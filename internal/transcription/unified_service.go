@@ -516,7 +516,7 @@ func (u *UnifiedTranscriptionService) convertParametersForModel(params models.Wh
 	case "openai_whisper":
 		return u.convertToOpenAIParams(params)
 	case "mlx_whisper":
-		return u.parametersToMap(params)
+		return u.convertToMLXParams(params)
 	default:
 		// Fallback to legacy conversion
 		return u.parametersToMap(params)
@@ -545,6 +545,39 @@ func (u *UnifiedTranscriptionService) convertToOpenAIParams(params models.Whispe
 	return paramMap
 }
 
+// mlxModelsByWhisperXName maps WhisperX model names to the MLX adapter's
+// models
+var mlxModelsByWhisperXName = map[string]string{
+	"base":           "mlx-community/whisper-base-mlx",
+	"large-v3":       "mlx-community/whisper-large-v3-mlx",
+	"large-v3-turbo": "mlx-community/whisper-large-v3-turbo",
+	"turbo":          "mlx-community/whisper-large-v3-turbo",
+}
+
+// convertToMLXParams converts to MLX Whisper-specific parameters. WhisperX
+// decoding defaults (beam size, best_of, ...) are deliberately not forwarded so
+// the MLX adapter's own defaults and quality presets apply.
+func (u *UnifiedTranscriptionService) convertToMLXParams(params models.WhisperXParams) map[string]interface{} {
+	paramMap := map[string]interface{}{
+		"task": params.Task,
+	}
+
+	// WhisperX names models by size; the MLX adapter takes mlx-community
+	// repositories. Sizes without an MLX equivalent fall back to the MLX
+	// default model.
+	if strings.Contains(params.Model, "/") {
+		paramMap["model"] = params.Model
+	} else if model, ok := mlxModelsByWhisperXName[params.Model]; ok {
+		paramMap["model"] = model
+	}
+
+	if params.Language != nil {
+		paramMap["language"] = *params.Language
+	}
+
+	return paramMap
+}
+
 // convertToParakeetParams converts to Parakeet-specific parameters
 func (u *UnifiedTranscriptionService) convertToParakeetParams(params models.WhisperXParams) map[string]interface{} {
 	return map[string]interface{}{
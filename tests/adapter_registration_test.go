@@ -1,7 +1,9 @@
 package tests
 
 import (
+	"errors"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"scriberr/internal/config"
@@ -159,3 +161,55 @@ func TestClearRegistry(t *testing.T) {
 		t.Errorf("Expected 0 diarization adapters after clear, got %d", len(diarizationAdapters))
 	}
 }
+
+// TestRegisterTranscriptionAdapterE tests duplicate detection and Unregister
+func TestRegisterTranscriptionAdapterE(t *testing.T) {
+	registry.ClearRegistry()
+	defer registry.ClearRegistry()
+
+	if err := registry.RegisterTranscriptionAdapterE("test", adapters.NewWhisperXAdapter("/tmp")); err != nil {
+		t.Fatalf("First registration failed: %v", err)
+	}
+	if !registry.Has("test") {
+		t.Fatal("Expected test to be registered")
+	}
+
+	err := registry.RegisterTranscriptionAdapterE("test", adapters.NewParakeetAdapter("/tmp"))
+	if !errors.Is(err, registry.ErrDuplicateModelID) {
+		t.Fatalf("Expected ErrDuplicateModelID, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "test") {
+		t.Errorf("Expected error to name the model ID, got %q", err)
+	}
+
+	registry.Unregister("test")
+	if registry.Has("test") {
+		t.Error("Expected test to be unregistered")
+	}
+	if err := registry.RegisterTranscriptionAdapterE("test", adapters.NewParakeetAdapter("/tmp")); err != nil {
+		t.Errorf("Registration after Unregister failed: %v", err)
+	}
+}
+
+// TestSetEnvRootReregisters tests that SetEnvRoot moves auto-registered
+// adapters by replacing their registrations
+func TestSetEnvRootReregisters(t *testing.T) {
+	registry.ClearRegistry()
+	defer registry.ClearRegistry()
+	defer adapters.SetEnvRoot(adapters.DefaultEnvRoot)
+
+	for _, root := range []string{t.TempDir(), t.TempDir()} {
+		adapters.SetEnvRoot(root)
+		adapter, err := registry.GetRegistry().GetTranscriptionAdapter("mlx_whisper")
+		if err != nil {
+			t.Fatalf("mlx_whisper not registered after SetEnvRoot(%s): %v", root, err)
+		}
+		mlx, ok := adapter.(*adapters.MLXAdapter)
+		if !ok {
+			t.Fatalf("Expected an *MLXAdapter, got %T", adapter)
+		}
+		if want := filepath.Join(root, "mlx-env"); mlx.ResolvedEnvPath() != want {
+			t.Errorf("Env path = %s, want %s", mlx.ResolvedEnvPath(), want)
+		}
+	}
+}
@@ -7,6 +7,14 @@ type ChangeItem = { type: 'Added' | 'Changed' | 'Fixed' | 'Deprecated' | 'Remove
 type Release = { version: string; date: string; tag?: 'Latest' | 'Beta'; notes: ChangeItem[] };
 
 const RELEASES: Release[] = [
+  {
+    version: '1.2.0',
+    date: '2026-10-15',
+    tag: 'Beta',
+    notes: [
+      { type: 'Changed', text: 'MLX transcription defaults to 4-bit quantized models: the -4bit mlx-community variant of the selected model is used when one exists, so existing setups download and run different weights than before. Set quantization to "none" to keep the full-precision model.' },
+    ],
+  },
   {
     version: '1.1.0',
     date: '2025-09-05',